@@ -1,41 +1,92 @@
 package xablogger
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 var coordinatorInstance coordinator
 
+// initOnce guards Init so that concurrent callers cannot race to reset coordinatorInstance; only the first
+// call takes effect.
+var initOnce sync.Once
+
+// defaultBufferSize is the sink pipeline queue capacity used when BufferSize is not provided
+const defaultBufferSize = 1000
+
+// defaultWorkers is how many goroutines drain the sink pipeline when Workers is not provided
+const defaultWorkers = 1
+
 type coordinator struct {
 	mainLogger     *logrus.Logger
 	defaultFields  map[string]interface{}
 	transactionMap map[string]*transaction
+	mapMux         sync.RWMutex
+
+	sinks         []Sink
+	bufferSize    int
+	workers       int
+	batchSize     int
+	batchInterval time.Duration
+	overflow      OverflowPolicy
+
+	pipeline *sinkPipeline
+	redactor *Redactor
+	sampler  Sampler
 }
 
 type transaction struct {
 	id       string
 	logger   *logrus.Entry
 	segments []Segment
+	sampled  bool
+	failed   bool
 	mux      sync.Mutex
 }
 
 // Init must be called before creating transactions. It inits all resources like the main logrus Logger, transactionMap and defaultFields fields.
-// The xablogger instance can be decorated with functional options provided at this package, like LogFormat, Hooks or DefaultFields
+// The xablogger instance can be decorated with functional options provided at this package, like LogFormat, Hooks, DefaultFields, Sinks or BufferSize.
+// Init is idempotent: only the first call takes effect, so concurrent callers racing at startup cannot blow
+// away state a competing goroutine already initialized.
 func Init(opts ...func(*coordinator)) {
-	coordinatorInstance = coordinator{}
-	coordinatorInstance.mainLogger = logrus.New()
-	coordinatorInstance.defaultFields = make(map[string]interface{})
-	coordinatorInstance.transactionMap = make(map[string]*transaction)
+	initOnce.Do(func() {
+		coordinatorInstance = coordinator{}
+		coordinatorInstance.mainLogger = logrus.New()
+		coordinatorInstance.defaultFields = make(map[string]interface{})
+		coordinatorInstance.transactionMap = make(map[string]*transaction)
+		coordinatorInstance.bufferSize = defaultBufferSize
+		coordinatorInstance.workers = defaultWorkers
+		coordinatorInstance.batchSize = 1
+		coordinatorInstance.batchInterval = time.Second
+		coordinatorInstance.overflow = DropNewest
+		coordinatorInstance.redactor = newRedactor()
+		coordinatorInstance.sampler = alwaysSampler{}
 
-	for _, opt := range opts {
-		opt(&coordinatorInstance)
-	}
+		for _, opt := range opts {
+			opt(&coordinatorInstance)
+		}
+
+		// sets the default audit=true field so that you can tell which log entries are for whole transactions or not
+		coordinatorInstance.defaultFields["audit"] = true
 
-	// sets the default audit=true field so that you can tell which log entries are for whole transactions or not
-	coordinatorInstance.defaultFields["audit"] = true
+		if coordinatorInstance.sinks == nil {
+			coordinatorInstance.sinks = []Sink{NewLogrusSink(coordinatorInstance.mainLogger, coordinatorInstance.defaultFields)}
+		}
+
+		coordinatorInstance.pipeline = newSinkPipeline(
+			coordinatorInstance.sinks,
+			coordinatorInstance.bufferSize,
+			coordinatorInstance.workers,
+			coordinatorInstance.batchSize,
+			coordinatorInstance.batchInterval,
+			coordinatorInstance.overflow,
+		)
+	})
 }
 
 // LogFormat sets the output format of the main logger
@@ -64,18 +115,115 @@ func DefaultFields(fields map[string]interface{}) func(*coordinator) {
 	}
 }
 
+// Sinks replaces the default logrus sink with the given sinks. Every entry produced by AppendSegment and
+// FlushTransaction is delivered to each one.
+func Sinks(sinks ...Sink) func(*coordinator) {
+	return func(x *coordinator) {
+		x.sinks = sinks
+	}
+}
+
+// BufferSize sets the capacity of the bounded channel sitting in front of the sink pipeline. Once full,
+// the configured OverflowPolicy (see Overflow) decides what happens to new entries.
+func BufferSize(n int) func(*coordinator) {
+	return func(x *coordinator) {
+		x.bufferSize = n
+	}
+}
+
+// Workers sets how many goroutines drain the sink pipeline concurrently. Defaults to 1.
+func Workers(n int) func(*coordinator) {
+	return func(x *coordinator) {
+		x.workers = n
+	}
+}
+
+// Batching configures the sink pipeline to flush either after size entries accumulate or after interval
+// elapses, whichever happens first. Defaults to a batch size of 1, i.e. no batching.
+func Batching(size int, interval time.Duration) func(*coordinator) {
+	return func(x *coordinator) {
+		x.batchSize = size
+		x.batchInterval = interval
+	}
+}
+
+// Overflow sets what happens to new entries once the sink pipeline's buffer is full. Defaults to
+// DropNewest.
+func Overflow(policy OverflowPolicy) func(*coordinator) {
+	return func(x *coordinator) {
+		x.overflow = policy
+	}
+}
+
+// DroppedEntries returns how many entries have been discarded so far because the sink pipeline's buffer was
+// full. Under the Block policy, an entry is only counted when Shutdown interrupts an enqueue waiting for
+// buffer space.
+func DroppedEntries() uint64 {
+	return coordinatorInstance.pipeline.DroppedCount()
+}
+
+// RedactHeaders configures header names (case-insensitive) whose values are replaced with
+// RedactedPlaceholder before being stored on HTTP segments
+func RedactHeaders(names ...string) func(*coordinator) {
+	return func(x *coordinator) {
+		for _, name := range names {
+			x.redactor.headers[strings.ToLower(name)] = struct{}{}
+		}
+	}
+}
+
+// RedactJSONPaths configures JSONPath-like expressions (e.g. "$.password") whose values are replaced
+// with RedactedPlaceholder in any JSON request/response body before being stored
+func RedactJSONPaths(paths ...string) func(*coordinator) {
+	return func(x *coordinator) {
+		x.redactor.jsonPaths = append(x.redactor.jsonPaths, paths...)
+	}
+}
+
+// RedactSQLParams configures SQL parameter names (case-insensitive) whose values are replaced with
+// RedactedPlaceholder before being stored on SQL segments
+func RedactSQLParams(names ...string) func(*coordinator) {
+	return func(x *coordinator) {
+		for _, name := range names {
+			x.redactor.sqlParams[strings.ToLower(name)] = struct{}{}
+		}
+	}
+}
+
+// MaxBodyBytes truncates request/response bodies to n bytes before they are stored. A value <= 0
+// disables truncation.
+func MaxBodyBytes(n int) func(*coordinator) {
+	return func(x *coordinator) {
+		x.redactor.maxBodyBytes = n
+	}
+}
+
+// Sampling configures the Sampler consulted when a transaction is created, and consulted again when a
+// segment of a sampled-out transaction fails, which can upgrade the transaction to sampled-in. Entries
+// produced while a transaction is sampled out are never delivered to the sink pipeline. Defaults to
+// keeping every transaction.
+func Sampling(sampler Sampler) func(*coordinator) {
+	return func(x *coordinator) {
+		x.sampler = sampler
+	}
+}
+
 // NewTransaction creates a new transaction instance at coordinator transactionMap.
 // The function will return an error if the transactionMap already contains an entry with the the provided transactionID
 func NewTransaction(transactionID string) error {
-	tx, exists := coordinatorInstance.transactionMap[transactionID]
+	coordinatorInstance.mapMux.Lock()
+	defer coordinatorInstance.mapMux.Unlock()
+
+	_, exists := coordinatorInstance.transactionMap[transactionID]
 	if exists {
 		return fmt.Errorf("TransactionID %s already exists inside transactions map", transactionID)
 	}
 
-	tx = &transaction{
+	tx := &transaction{
 		id:       transactionID,
 		segments: []Segment{},
 		logger:   coordinatorInstance.mainLogger.WithFields(coordinatorInstance.defaultFields),
+		sampled:  coordinatorInstance.sampler.Sample(transactionID, false),
 	}
 
 	coordinatorInstance.transactionMap[transactionID] = tx
@@ -85,44 +233,98 @@ func NewTransaction(transactionID string) error {
 // AppendSegment is used to add an extra segment to a given transaction and generate the separate, non-audit log entry for the segment.
 // Please note that the whole audit entry will only be generate by calling the Flush function
 func AppendSegment(transactionID string, segment Segment) error {
-
-	segmentEntry := coordinatorInstance.mainLogger.WithFields(map[string]interface{}{
-		"segment.type": segment.Type(),
-		"segment.data": segment.Fields(),
-		"audit":        false,
-	}).WithFields(coordinatorInstance.defaultFields)
-
-	if segment.HasFailed() {
-		segmentEntry.Error()
-	} else {
-		segmentEntry.Info()
-	}
-
+	coordinatorInstance.mapMux.RLock()
 	tx, exists := coordinatorInstance.transactionMap[transactionID]
+	coordinatorInstance.mapMux.RUnlock()
 	if !exists {
 		return fmt.Errorf("Transaction %s not found", transactionID)
 	}
 
 	tx.mux.Lock()
+	// a transaction sampled out at creation time can still be upgraded to sampled-in the moment one of
+	// its segments fails, so samplers like ErrorPrioritySampler can keep failing transactions
+	if !tx.sampled && segment.HasFailed() && coordinatorInstance.sampler.Sample(transactionID, true) {
+		tx.sampled = true
+	}
+	if segment.HasFailed() {
+		tx.failed = true
+	}
+	tx.logger = tx.logger.WithFields(segment.Fields())
+	sampled := tx.sampled
 	tx.segments = append(tx.segments, segment)
 	tx.mux.Unlock()
+
+	if sampled {
+		coordinatorInstance.pipeline.enqueue(Entry{
+			TransactionID: transactionID,
+			SegmentType:   segment.Type(),
+			Audit:         false,
+			Failed:        segment.HasFailed(),
+			Fields:        segment.Fields(),
+			Timestamp:     time.Now(),
+		})
+	}
+
 	return nil
 }
 
 // FlushTransaction ends a transaction and generates the audit trail log event.
 // The function will return an error if the transactionID cannot be found
 func FlushTransaction(transactionID string) error {
+	coordinatorInstance.mapMux.Lock()
 	tx, exists := coordinatorInstance.transactionMap[transactionID]
+	if exists {
+		delete(coordinatorInstance.transactionMap, transactionID)
+	}
+	coordinatorInstance.mapMux.Unlock()
+
 	if !exists {
 		return fmt.Errorf("TransactionID %s not found", transactionID)
 	}
 
-	if _, failed := tx.logger.Data["error"]; failed {
-		tx.logger.Error()
-	} else {
-		tx.logger.Info()
+	tx.mux.Lock()
+	sampled := tx.sampled
+	failed := tx.failed
+	fields := tx.logger.Data
+	tx.mux.Unlock()
+
+	if sampled {
+		coordinatorInstance.pipeline.enqueue(Entry{
+			TransactionID: transactionID,
+			Audit:         true,
+			Failed:        failed,
+			Fields:        fields,
+			Timestamp:     time.Now(),
+		})
 	}
 
-	delete(coordinatorInstance.transactionMap, transactionID)
 	return nil
 }
+
+// Shutdown flushes every transaction still open in transactionMap, then drains and closes the sink
+// pipeline, stopping early and returning ctx.Err() if ctx is done before every transaction has been
+// flushed. Call it once, when the process is shutting down.
+func Shutdown(ctx context.Context) error {
+	defer coordinatorInstance.pipeline.close()
+
+	coordinatorInstance.mapMux.RLock()
+	ids := make([]string, 0, len(coordinatorInstance.transactionMap))
+	for id := range coordinatorInstance.transactionMap {
+		ids = append(ids, id)
+	}
+	coordinatorInstance.mapMux.RUnlock()
+
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		_ = FlushTransaction(id)
+	}
+
+	// Drain the queue and worker batches so everything enqueued above has reached Sink.Write before we ask
+	// the sinks to Flush; otherwise a sink that only persists on Flush would silently lose this last batch.
+	coordinatorInstance.pipeline.drain()
+	return coordinatorInstance.pipeline.flush()
+}
@@ -0,0 +1,200 @@
+package xablogger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	coordinatorTestInitOnce sync.Once
+	coordinatorTestSink     = &auditCapturingSink{}
+)
+
+// ensureCoordinatorTestInit calls Init exactly once for the whole test binary: coordinatorInstance is a
+// package-level singleton guarded by its own sync.Once, so only the first Init call in the process takes
+// effect. Every coordinator-level test in this package shares the resulting instance and scopes itself with
+// a unique transaction ID (t.Name()) rather than expecting a fresh coordinator.
+func ensureCoordinatorTestInit() {
+	coordinatorTestInitOnce.Do(func() {
+		Init(Sinks(coordinatorTestSink), Batching(1, time.Millisecond))
+	})
+}
+
+// auditCapturingSink records every Entry written to it, safe for concurrent use since the sink pipeline
+// delivers from worker goroutines.
+type auditCapturingSink struct {
+	mux     sync.Mutex
+	entries []Entry
+}
+
+func (s *auditCapturingSink) Write(e Entry) error {
+	s.mux.Lock()
+	s.entries = append(s.entries, e)
+	s.mux.Unlock()
+	return nil
+}
+
+func (s *auditCapturingSink) Flush() error { return nil }
+func (s *auditCapturingSink) Close() error { return nil }
+
+// auditFor returns the most recent audit Entry recorded for transactionID, if any
+func (s *auditCapturingSink) auditFor(transactionID string) (Entry, bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if s.entries[i].Audit && s.entries[i].TransactionID == transactionID {
+			return s.entries[i], true
+		}
+	}
+	return Entry{}, false
+}
+
+type fakeSegment struct {
+	segType string
+	failed  bool
+	fields  map[string]interface{}
+}
+
+func newFakeSegment(segType string, fields map[string]interface{}) *fakeSegment {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	return &fakeSegment{segType: segType, fields: fields}
+}
+
+func (s *fakeSegment) Type() string             { return s.segType }
+func (s *fakeSegment) Context() context.Context { return context.Background() }
+func (s *fakeSegment) Fields() map[string]interface{} {
+	return s.fields
+}
+func (s *fakeSegment) HasFailed() bool { return s.failed }
+func (s *fakeSegment) Done()           {}
+func (s *fakeSegment) Failed(err error) {
+	s.failed = true
+	s.fields["error"] = err.Error()
+}
+
+func waitForCoordinator(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestCoordinatorLifecycle exercises NewTransaction/AppendSegment/FlushTransaction/RecoverTransaction/Shutdown
+// as ordered subtests against the shared package-level coordinator, ending with Shutdown: once Shutdown runs,
+// the sink pipeline's workers stop for good, so nothing after it can observe new entries reaching the sink.
+func TestCoordinatorLifecycle(t *testing.T) {
+	ensureCoordinatorTestInit()
+
+	t.Run("AppendAndFlush", func(t *testing.T) {
+		txID := "lifecycle-" + t.Name()
+		if err := NewTransaction(txID); err != nil {
+			t.Fatalf("NewTransaction() = %v", err)
+		}
+
+		if err := NewTransaction(txID); err == nil {
+			t.Error("NewTransaction() with a duplicate ID should error")
+		}
+
+		ok := newFakeSegment("ok", map[string]interface{}{"status": "fine"})
+		failing := newFakeSegment("bad", map[string]interface{}{"status": "broken"})
+		failing.Failed(errors.New("boom"))
+
+		if err := AppendSegment(txID, ok); err != nil {
+			t.Fatalf("AppendSegment(ok) = %v", err)
+		}
+		if err := AppendSegment(txID, failing); err != nil {
+			t.Fatalf("AppendSegment(failing) = %v", err)
+		}
+
+		if err := FlushTransaction(txID); err != nil {
+			t.Fatalf("FlushTransaction() = %v", err)
+		}
+		if err := FlushTransaction(txID); err == nil {
+			t.Error("FlushTransaction() on an already-flushed transaction should error")
+		}
+
+		waitForCoordinator(t, time.Second, func() bool {
+			_, ok := coordinatorTestSink.auditFor(txID)
+			return ok
+		})
+
+		audit, _ := coordinatorTestSink.auditFor(txID)
+		if !audit.Failed {
+			t.Error("audit entry Failed = false, want true since one appended segment failed")
+		}
+		if audit.Fields["status"] != "broken" {
+			t.Errorf("audit entry Fields[status] = %v, want the last-appended segment's value", audit.Fields["status"])
+		}
+	})
+
+	t.Run("AppendToUnknownTransaction", func(t *testing.T) {
+		if err := AppendSegment("no-such-transaction-"+t.Name(), newFakeSegment("fake", nil)); err == nil {
+			t.Error("AppendSegment() on an unknown transaction should error")
+		}
+	})
+
+	t.Run("RecoverTransaction", func(t *testing.T) {
+		txID := "recover-" + t.Name()
+		if err := NewTransaction(txID); err != nil {
+			t.Fatalf("NewTransaction() = %v", err)
+		}
+
+		func() {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					t.Fatal("RecoverTransaction() should re-panic after recording the panic")
+				}
+				if recovered != "boom" {
+					t.Errorf("recovered value = %v, want %q", recovered, "boom")
+				}
+			}()
+			defer RecoverTransaction(txID)
+			panic("boom")
+		}()
+
+		waitForCoordinator(t, time.Second, func() bool {
+			_, ok := coordinatorTestSink.auditFor(txID)
+			return ok
+		})
+
+		audit, _ := coordinatorTestSink.auditFor(txID)
+		if !audit.Failed {
+			t.Error("audit entry Failed = false, want true after a recovered panic")
+		}
+		if audit.Fields["error"] != "boom" {
+			t.Errorf("audit entry Fields[error] = %v, want %q", audit.Fields["error"], "boom")
+		}
+	})
+
+	t.Run("ShutdownFlushesOpenTransactions", func(t *testing.T) {
+		txID := "shutdown-" + t.Name()
+		if err := NewTransaction(txID); err != nil {
+			t.Fatalf("NewTransaction() = %v", err)
+		}
+		_ = AppendSegment(txID, newFakeSegment("ok", map[string]interface{}{"status": "fine"}))
+
+		if err := Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() = %v", err)
+		}
+
+		if _, ok := coordinatorTestSink.auditFor(txID); !ok {
+			t.Error("Shutdown() should flush every transaction still open in transactionMap")
+		}
+
+		// a second Shutdown must not panic or block: pipeline.close() is documented as safe to call more
+		// than once
+		if err := Shutdown(context.Background()); err != nil {
+			t.Fatalf("second Shutdown() = %v", err)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+// Package httpwrap provides a zero-boilerplate http.Client wrapper that automatically emits an
+// xablogger.HTTPSegment for every outbound call.
+package httpwrap
+
+import (
+	"net/http"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// Client wraps an http.Client so that every Do call creates, populates and appends an xablogger.HTTPSegment
+// bound to the transaction stored in the request's context.
+type Client struct {
+	*http.Client
+}
+
+// NewClient builds a Client wrapping a new *http.Client using http.DefaultTransport. Assign Client.Client to
+// use a different *http.Client, e.g. one with a custom Transport or timeout.
+func NewClient() *Client {
+	return &Client{Client: &http.Client{Transport: http.DefaultTransport}}
+}
+
+// Do performs req, emitting an HTTPSegment bound to the transaction carried by req.Context(). The segment's
+// W3C traceparent/tracestate headers are injected into req before it is sent, so the remote server can link
+// its own spans back to this call.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	segment := xablogger.NewServerSegment(req.Context(), req)
+	segment.InjectHeaders(req)
+
+	res, err := c.Client.Do(req)
+	if err != nil {
+		segment.Failed(err)
+	} else {
+		segment.Response(res)
+	}
+	segment.Done()
+
+	_ = xablogger.AppendSegmentContext(req.Context(), segment)
+
+	return res, err
+}
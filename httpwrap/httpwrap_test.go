@@ -0,0 +1,97 @@
+package httpwrap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// recordingSink captures every Entry written to it, safe for concurrent use since the sink pipeline delivers
+// from worker goroutines.
+type recordingSink struct {
+	mux     sync.Mutex
+	entries []xablogger.Entry
+}
+
+func (s *recordingSink) Write(e xablogger.Entry) error {
+	s.mux.Lock()
+	s.entries = append(s.entries, e)
+	s.mux.Unlock()
+	return nil
+}
+
+func (s *recordingSink) Flush() error { return nil }
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) len() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.entries)
+}
+
+var (
+	testInitOnce sync.Once
+	testSink     = &recordingSink{}
+)
+
+func ensureTestInit() {
+	testInitOnce.Do(func() {
+		xablogger.Init(xablogger.Sinks(testSink), xablogger.Batching(1, time.Millisecond))
+	})
+}
+
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestClientDoBodylessGET is a regression test: http.NewRequest(http.MethodGet, url, nil) leaves req.Body
+// nil (not http.NoBody), and NewServerSegment used to assume any non-http.NoBody Body was safe to read,
+// nil-pointer-panicking on this, the most common outbound call shape.
+func TestClientDoBodylessGET(t *testing.T) {
+	ensureTestInit()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	txID := "httpwrap-get-" + t.Name()
+	ctx, err := xablogger.NewTransactionWithContext(context.Background(), txID)
+	if err != nil {
+		t.Fatalf("NewTransactionWithContext() = %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequestWithContext() = %v", err)
+	}
+
+	client := NewClient()
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() = %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status code = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	if err := xablogger.FlushTransactionContext(ctx); err != nil {
+		t.Fatalf("FlushTransactionContext() = %v", err)
+	}
+
+	waitFor(t, time.Second, func() bool { return testSink.len() > 0 })
+}
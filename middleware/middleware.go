@@ -0,0 +1,127 @@
+// Package middleware provides zero-boilerplate xablogger instrumentation for net/http servers.
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/segmentio/ksuid"
+
+	"github.com/andretanaka/xablogger"
+	xablohttp "github.com/andretanaka/xablogger/segments/http"
+)
+
+// defaultBodyCaptureCap bounds how much of the response body responseRecorder buffers for logging when
+// xablogger.ActiveRedactor().MaxBodyBytes() hasn't been configured (MaxBodyBytes), so a handler streaming
+// an unbounded response doesn't also balloon memory in the recorder.
+const defaultBodyCaptureCap = 1 << 20 // 1MiB
+
+// responseRecorder wraps http.ResponseWriter to capture the status code and body written by the wrapped
+// handler, so they can be attached to the transaction's ServerSegment without the handler needing to know
+// xablogger exists. Capture of the body is capped at bodyCap bytes; bytes beyond the cap are still written
+// to the underlying ResponseWriter, just not retained for logging. Flush, Hijack and CloseNotify are
+// forwarded to the underlying ResponseWriter when it supports them, so streamed responses, SSE and
+// websocket upgrades keep working through the middleware.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+	bodyCap    int
+}
+
+func (w *responseRecorder) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if remaining := w.bodyCap - w.body.Len(); remaining > 0 {
+		if remaining > len(b) {
+			remaining = len(b)
+		}
+		w.body.Write(b[:remaining])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush forwards to the underlying ResponseWriter's Flush, if it implements http.Flusher, so streamed
+// responses are still flushed to the client as they're written.
+func (w *responseRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack forwards to the underlying ResponseWriter's Hijack, if it implements http.Hijacker, so websocket
+// upgrades and other connection takeovers still work through the middleware.
+func (w *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("xablogger: underlying ResponseWriter does not support Hijack")
+	}
+	return hijacker.Hijack()
+}
+
+// CloseNotify forwards to the underlying ResponseWriter's CloseNotify, if it implements
+// http.CloseNotifier, so handlers watching for client disconnects still get notified.
+func (w *responseRecorder) CloseNotify() <-chan bool {
+	notifier, ok := w.ResponseWriter.(http.CloseNotifier)
+	if !ok {
+		return nil
+	}
+	return notifier.CloseNotify()
+}
+
+// Handler wraps next with xablogger instrumentation. It derives a transaction ID from the inbound
+// X-Request-ID header, generating a KSUID when the header is absent, attaches a ServerSegment, captures the
+// response status code and body, and flushes the transaction once next returns. A panic inside next is
+// recovered, recorded as a failed segment, and re-panicked so it still reaches the caller's own recovery or
+// crash reporting.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transactionID := r.Header.Get("X-Request-ID")
+		if transactionID == "" {
+			transactionID = ksuid.New().String()
+		}
+
+		ctx, err := xablogger.NewTransactionWithContext(r.Context(), transactionID)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		segment := xablohttp.NewServerSegment(ctx, r)
+
+		// carry the span-bearing context forward so that segments a handler creates from r.Context() (a
+		// sqlwrap query, an httpwrap call, a nested segment) are parented under this request's span instead
+		// of starting disconnected root spans. segment.Context() derives from ctx, so the transaction value
+		// NewTransactionWithContext stored is still reachable from it.
+		r = r.WithContext(segment.Context())
+
+		bodyCap := xablogger.ActiveRedactor().MaxBodyBytes()
+		if bodyCap <= 0 {
+			bodyCap = defaultBodyCaptureCap
+		}
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK, bodyCap: bodyCap}
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				segment.Failed(fmt.Errorf("panic: %v", recovered))
+				segment.Done()
+				_ = xablogger.AppendSegmentContext(ctx, segment)
+				_ = xablogger.FlushTransactionContext(ctx)
+				panic(recovered)
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+
+		segment.RawResponse(recorder.statusCode, recorder.Header(), recorder.body.Bytes())
+		segment.Done()
+		_ = xablogger.AppendSegmentContext(ctx, segment)
+		_ = xablogger.FlushTransactionContext(ctx)
+	})
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// recordingSink captures every Entry written to it, safe for concurrent use since the sink pipeline delivers
+// from worker goroutines.
+type recordingSink struct {
+	mux     sync.Mutex
+	entries []xablogger.Entry
+}
+
+func (s *recordingSink) Write(e xablogger.Entry) error {
+	s.mux.Lock()
+	s.entries = append(s.entries, e)
+	s.mux.Unlock()
+	return nil
+}
+
+func (s *recordingSink) Flush() error { return nil }
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) auditFor(transactionID string) (xablogger.Entry, bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if s.entries[i].Audit && s.entries[i].TransactionID == transactionID {
+			return s.entries[i], true
+		}
+	}
+	return xablogger.Entry{}, false
+}
+
+var (
+	testInitOnce sync.Once
+	testSink     = &recordingSink{}
+)
+
+func ensureTestInit() {
+	testInitOnce.Do(func() {
+		xablogger.Init(xablogger.Sinks(testSink), xablogger.Batching(1, time.Millisecond))
+	})
+}
+
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHandlerRoundTrip(t *testing.T) {
+	ensureTestInit()
+
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest() = %v", err)
+	}
+	txID := "middleware-" + t.Name()
+	req.Header.Set("X-Request-ID", txID)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() = %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusTeapot {
+		t.Errorf("status code = %d, want %d", res.StatusCode, http.StatusTeapot)
+	}
+
+	waitFor(t, time.Second, func() bool {
+		_, ok := testSink.auditFor(txID)
+		return ok
+	})
+
+	audit, _ := testSink.auditFor(txID)
+	if audit.Failed {
+		t.Error("audit entry Failed = true, want false for a successful request")
+	}
+}
+
+func TestHandlerRecoversPanic(t *testing.T) {
+	ensureTestInit()
+
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	txID := "middleware-panic-" + t.Name()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", txID)
+	recorder := httptest.NewRecorder()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Handler should re-panic after recording the panic")
+			}
+		}()
+		handler.ServeHTTP(recorder, req)
+	}()
+
+	waitFor(t, time.Second, func() bool {
+		_, ok := testSink.auditFor(txID)
+		return ok
+	})
+
+	audit, _ := testSink.auditFor(txID)
+	if !audit.Failed {
+		t.Error("audit entry Failed = false, want true after a recovered panic")
+	}
+}
@@ -0,0 +1,183 @@
+package xablogger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy controls what happens when the sink pipeline's buffer is full and a new entry arrives.
+type OverflowPolicy int
+
+const (
+	// DropNewest discards the entry currently being enqueued, keeping everything already buffered
+	DropNewest OverflowPolicy = iota
+	// DropOldest discards the single oldest buffered entry to make room for the new one
+	DropOldest
+	// Block waits until buffer space frees up, applying backpressure to the caller
+	Block
+)
+
+// sinkPipeline fans entries out to every configured Sink through a bounded channel drained by a pool of
+// worker goroutines, so AppendSegment/FlushTransaction do not wait on slow sinks; only the Block overflow
+// policy makes them wait, and only while the buffer is full.
+type sinkPipeline struct {
+	sinks         []Sink
+	queue         chan Entry
+	batchSize     int
+	batchInterval time.Duration
+	overflow      OverflowPolicy
+	dropped       uint64
+	wg            sync.WaitGroup
+	drainOnce     sync.Once
+	closeOnce     sync.Once
+	// closing is closed by drain() to signal shutdown. queue itself is never closed: a goroutine can be
+	// blocked sending into it under the Block overflow policy at the exact moment Shutdown runs, and closing
+	// a channel out from under a blocked sender panics with "send on closed channel". Selecting on closing
+	// instead lets a blocked enqueue() bail out safely, and lets workers drain whatever's left without racing
+	// a close.
+	closing chan struct{}
+}
+
+// newSinkPipeline starts workers worker goroutines draining queue into sinks, batching up to batchSize
+// entries or batchInterval, whichever comes first.
+func newSinkPipeline(sinks []Sink, bufferSize, workers, batchSize int, batchInterval time.Duration, overflow OverflowPolicy) *sinkPipeline {
+	if workers <= 0 {
+		workers = 1
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if batchInterval <= 0 {
+		batchInterval = time.Second
+	}
+
+	p := &sinkPipeline{
+		sinks:         sinks,
+		queue:         make(chan Entry, bufferSize),
+		batchSize:     batchSize,
+		batchInterval: batchInterval,
+		overflow:      overflow,
+		closing:       make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.worker()
+	}
+
+	return p
+}
+
+// enqueue adds entry to the pipeline, applying the configured OverflowPolicy if the buffer is full
+func (p *sinkPipeline) enqueue(entry Entry) {
+	select {
+	case p.queue <- entry:
+		return
+	default:
+	}
+
+	switch p.overflow {
+	case Block:
+		select {
+		case p.queue <- entry:
+		case <-p.closing:
+			atomic.AddUint64(&p.dropped, 1)
+		}
+	case DropOldest:
+		select {
+		case <-p.queue:
+			atomic.AddUint64(&p.dropped, 1)
+		default:
+		}
+		select {
+		case p.queue <- entry:
+		default:
+			atomic.AddUint64(&p.dropped, 1)
+		}
+	default: // DropNewest
+		atomic.AddUint64(&p.dropped, 1)
+	}
+}
+
+// DroppedCount returns how many entries have been discarded due to buffer overflow so far
+func (p *sinkPipeline) DroppedCount() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
+
+func (p *sinkPipeline) worker() {
+	defer p.wg.Done()
+
+	batch := make([]Entry, 0, p.batchSize)
+	ticker := time.NewTicker(p.batchInterval)
+	defer ticker.Stop()
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		for _, entry := range batch {
+			for _, sink := range p.sinks {
+				_ = sink.Write(entry)
+			}
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry := <-p.queue:
+			batch = append(batch, entry)
+			if len(batch) >= p.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-p.closing:
+			// drain whatever's already buffered without blocking, then flush and exit
+			for {
+				select {
+				case entry := <-p.queue:
+					batch = append(batch, entry)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush calls Flush on every configured sink
+func (p *sinkPipeline) flush() error {
+	var firstErr error
+	for _, sink := range p.sinks {
+		if err := sink.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// drain signals every worker to write whatever's buffered in queue and its local batch out to the sinks,
+// then waits for them to finish. Safe to call multiple times; only the first call has effect. Callers that
+// need the result of a Sink.Flush to reflect entries enqueued just before shutdown must call drain before
+// flush, since entries sitting in queue or a worker's in-progress batch haven't reached Sink.Write yet.
+func (p *sinkPipeline) drain() {
+	p.drainOnce.Do(func() {
+		close(p.closing)
+		p.wg.Wait()
+	})
+}
+
+// close drains the queue, flushes every sink so any internally-buffered partial batch is persisted, then
+// releases every sink. Safe to call multiple times.
+func (p *sinkPipeline) close() {
+	p.closeOnce.Do(func() {
+		p.drain()
+		_ = p.flush()
+		for _, sink := range p.sinks {
+			_ = sink.Close()
+		}
+	})
+}
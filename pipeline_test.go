@@ -0,0 +1,202 @@
+package xablogger
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink is a Sink that appends every written Entry to a slice, guarded by a mutex since the
+// pipeline may run multiple workers concurrently.
+type recordingSink struct {
+	mux     sync.Mutex
+	entries []Entry
+	closed  bool
+}
+
+func (s *recordingSink) Write(e Entry) error {
+	s.mux.Lock()
+	s.entries = append(s.entries, e)
+	s.mux.Unlock()
+	return nil
+}
+
+func (s *recordingSink) Flush() error { return nil }
+
+func (s *recordingSink) Close() error {
+	s.mux.Lock()
+	s.closed = true
+	s.mux.Unlock()
+	return nil
+}
+
+func (s *recordingSink) len() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.entries)
+}
+
+// countingFlushSink wraps recordingSink and records, for each Flush() call, how many entries had already
+// reached Write() at that point — used to assert that a drain happens before Flush, not after.
+type countingFlushSink struct {
+	recordingSink
+	flushMux    sync.Mutex
+	flushCounts []int
+}
+
+func (s *countingFlushSink) Flush() error {
+	s.flushMux.Lock()
+	s.flushCounts = append(s.flushCounts, s.len())
+	s.flushMux.Unlock()
+	return nil
+}
+
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSinkPipelineDeliversEntries(t *testing.T) {
+	sink := &recordingSink{}
+	p := newSinkPipeline([]Sink{sink}, 10, 1, 1, time.Hour, DropNewest)
+	defer p.close()
+
+	p.enqueue(Entry{TransactionID: "tx1"})
+	p.enqueue(Entry{TransactionID: "tx2"})
+
+	waitFor(t, time.Second, func() bool { return sink.len() == 2 })
+}
+
+func TestSinkPipelineBatchesBySize(t *testing.T) {
+	sink := &recordingSink{}
+	p := newSinkPipeline([]Sink{sink}, 10, 1, 3, time.Hour, DropNewest)
+	defer p.close()
+
+	p.enqueue(Entry{TransactionID: "tx1"})
+	p.enqueue(Entry{TransactionID: "tx2"})
+	time.Sleep(20 * time.Millisecond)
+	if got := sink.len(); got != 0 {
+		t.Fatalf("sink received %d entries before the batch filled, want 0", got)
+	}
+
+	p.enqueue(Entry{TransactionID: "tx3"})
+	waitFor(t, time.Second, func() bool { return sink.len() == 3 })
+}
+
+func TestSinkPipelineBatchesByInterval(t *testing.T) {
+	sink := &recordingSink{}
+	p := newSinkPipeline([]Sink{sink}, 10, 1, 100, 10*time.Millisecond, DropNewest)
+	defer p.close()
+
+	p.enqueue(Entry{TransactionID: "tx1"})
+
+	waitFor(t, time.Second, func() bool { return sink.len() == 1 })
+}
+
+func TestSinkPipelineOverflowDropNewest(t *testing.T) {
+	// built directly, bypassing newSinkPipeline, so no worker goroutine drains the queue concurrently and
+	// the buffer fill/overflow behavior below is deterministic
+	p := &sinkPipeline{queue: make(chan Entry, 1), overflow: DropNewest}
+
+	p.enqueue(Entry{TransactionID: "keep"})
+	p.enqueue(Entry{TransactionID: "dropped-1"})
+	p.enqueue(Entry{TransactionID: "dropped-2"})
+
+	if got := p.DroppedCount(); got != 2 {
+		t.Errorf("DroppedCount() = %d, want 2", got)
+	}
+	if got := len(p.queue); got != 1 {
+		t.Errorf("queue length = %d, want 1 (the first entry enqueued)", got)
+	}
+}
+
+func TestSinkPipelineOverflowDropOldest(t *testing.T) {
+	p := &sinkPipeline{queue: make(chan Entry, 1), overflow: DropOldest}
+
+	p.enqueue(Entry{TransactionID: "oldest"})
+	p.enqueue(Entry{TransactionID: "newest"})
+
+	if got := p.DroppedCount(); got != 1 {
+		t.Errorf("DroppedCount() = %d, want 1", got)
+	}
+
+	queued := <-p.queue
+	if queued.TransactionID != "newest" {
+		t.Errorf("queue held %q, want the newest entry to have replaced the oldest", queued.TransactionID)
+	}
+}
+
+func TestSinkPipelineOverflowBlock(t *testing.T) {
+	sink := &recordingSink{}
+	p := newSinkPipeline([]Sink{sink}, 1, 1, 1, time.Hour, Block)
+	defer p.close()
+
+	// the single worker drains the queue as fast as it's filled, so Block should never deadlock
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 50; i++ {
+			p.enqueue(Entry{TransactionID: "tx"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Block overflow policy deadlocked instead of draining through the worker")
+	}
+
+	waitFor(t, time.Second, func() bool { return sink.len() == 50 })
+}
+
+func TestSinkPipelineFlushAndClose(t *testing.T) {
+	sink := &recordingSink{}
+	p := newSinkPipeline([]Sink{sink}, 10, 2, 1, time.Hour, DropNewest)
+
+	p.enqueue(Entry{TransactionID: "tx1"})
+	waitFor(t, time.Second, func() bool { return sink.len() == 1 })
+
+	if err := p.flush(); err != nil {
+		t.Fatalf("flush() = %v, want nil", err)
+	}
+
+	p.close()
+	if !sink.closed {
+		t.Error("close() should call Close() on every configured sink")
+	}
+
+	// close is documented as safe to call more than once
+	p.close()
+}
+
+func TestSinkPipelineDrainBeforeFlush(t *testing.T) {
+	sink := &countingFlushSink{}
+	// A batchSize bigger than the entries enqueued and a long batchInterval mean the worker holds them in
+	// its local batch rather than writing them out on its own, reproducing the window flush() must cover.
+	p := newSinkPipeline([]Sink{sink}, 10, 1, 5, time.Hour, DropNewest)
+
+	for i := 0; i < 3; i++ {
+		p.enqueue(Entry{TransactionID: "tx"})
+	}
+
+	p.drain()
+	if n := sink.len(); n != 3 {
+		t.Fatalf("sink saw %d entries after drain(), want 3", n)
+	}
+
+	if err := p.flush(); err != nil {
+		t.Fatalf("flush() = %v, want nil", err)
+	}
+
+	if len(sink.flushCounts) != 1 || sink.flushCounts[0] != 3 {
+		t.Errorf("flush() should observe all 3 entries already written, got flushCounts=%v", sink.flushCounts)
+	}
+
+	p.close()
+}
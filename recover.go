@@ -0,0 +1,77 @@
+package xablogger
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// panicSegment carries the recovered panic value and a stack trace for a transaction that was aborted by a
+// panic. It is never constructed directly; use RecoverTransaction.
+type panicSegment struct {
+	start time.Time
+	data  map[string]interface{}
+}
+
+func newPanicSegment(recovered interface{}, stack []byte) *panicSegment {
+	return &panicSegment{
+		start: time.Now(),
+		data: map[string]interface{}{
+			"error": fmt.Sprintf("%v", recovered),
+			"stack": string(stack),
+		},
+	}
+}
+
+// Type returns the segment type
+func (s *panicSegment) Type() string {
+	return "panic"
+}
+
+// Context returns context.Background, since a panic segment has no meaningful parent span to attach to
+func (s *panicSegment) Context() context.Context {
+	return context.Background()
+}
+
+// Failed replaces the recorded error with err. A panicSegment always represents a failure, so HasFailed
+// reports true whether or not Failed is called.
+func (s *panicSegment) Failed(err error) {
+	s.data["error"] = err.Error()
+}
+
+// Fields return the data fields
+func (s *panicSegment) Fields() map[string]interface{} {
+	return s.data
+}
+
+// HasFailed always returns true
+func (s *panicSegment) HasFailed() bool {
+	return true
+}
+
+// Done stops measuring elapsed time
+func (s *panicSegment) Done() {
+	s.data["elapsed_ms"] = int(time.Since(s.start) / time.Millisecond)
+}
+
+// RecoverTransaction is intended for use with defer. If a panic is in flight, it converts the panic into a
+// failed segment carrying the recovered value and a stack trace (via runtime.Stack), appends that segment to
+// transactionID, flushes the transaction, and re-panics so the panic still reaches the caller's own recovery
+// or crash reporting. If there is no panic in flight, it does nothing.
+func RecoverTransaction(transactionID string) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	stack := make([]byte, 64<<10)
+	stack = stack[:runtime.Stack(stack, false)]
+
+	segment := newPanicSegment(recovered, stack)
+	segment.Done()
+	_ = AppendSegment(transactionID, segment)
+	_ = FlushTransaction(transactionID)
+
+	panic(recovered)
+}
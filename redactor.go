@@ -0,0 +1,156 @@
+package xablogger
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// RedactedPlaceholder replaces any value matched by the configured Redactor
+const RedactedPlaceholder = "[REDACTED]"
+
+// builtin patterns for common PII that gets redacted out of bodies regardless of configuration
+var (
+	creditCardPattern = regexp.MustCompile(`\b(?:\d[ -]*?){13,19}\b`)
+	emailPattern      = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+)
+
+// Redactor strips sensitive data out of segment fields before they are stored and logged. Configure one
+// through Init's RedactHeaders, RedactJSONPaths, RedactSQLParams and MaxBodyBytes options.
+type Redactor struct {
+	headers      map[string]struct{}
+	jsonPaths    []string
+	sqlParams    map[string]struct{}
+	maxBodyBytes int
+}
+
+func newRedactor() *Redactor {
+	return &Redactor{
+		headers:   make(map[string]struct{}),
+		sqlParams: make(map[string]struct{}),
+	}
+}
+
+// activeRedactor returns the Redactor configured on the package-level coordinator instance
+func activeRedactor() *Redactor {
+	return coordinatorInstance.redactor
+}
+
+// ActiveRedactor returns the Redactor configured on Init, for use by segment implementations living outside
+// this package (e.g. segments/http)
+func ActiveRedactor() *Redactor {
+	return activeRedactor()
+}
+
+// MaxBodyBytes returns the configured body truncation limit, or 0 if none is configured (including when r
+// is nil). Callers outside this package that buffer a body themselves (e.g. middleware.responseRecorder)
+// can use it to cap their own buffering to the same limit Body() truncates to.
+func (r *Redactor) MaxBodyBytes() int {
+	if r == nil {
+		return 0
+	}
+	return r.maxBodyBytes
+}
+
+// Headers returns a copy of h with the configured header names' values replaced with RedactedPlaceholder
+func (r *Redactor) Headers(h http.Header) http.Header {
+	if r == nil || len(h) == 0 {
+		return h
+	}
+
+	redacted := make(http.Header, len(h))
+	for k, v := range h {
+		if _, found := r.headers[strings.ToLower(k)]; found {
+			redacted[k] = []string{RedactedPlaceholder}
+			continue
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// SQLParams returns a copy of params with the configured parameter names' values replaced with
+// RedactedPlaceholder
+func (r *Redactor) SQLParams(params map[string]interface{}) map[string]interface{} {
+	if r == nil || len(params) == 0 {
+		return params
+	}
+
+	redacted := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if _, found := r.sqlParams[strings.ToLower(k)]; found {
+			redacted[k] = RedactedPlaceholder
+			continue
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// Body redacts a request/response body: JSON paths configured through RedactJSONPaths are blanked out
+// when the body parses as JSON, built-in patterns for credit card numbers and e-mail addresses are always
+// replaced, and the result is truncated to MaxBodyBytes if configured.
+func (r *Redactor) Body(body string) string {
+	if r == nil {
+		return body
+	}
+
+	body = r.redactJSONPaths(body)
+	body = creditCardPattern.ReplaceAllString(body, RedactedPlaceholder)
+	body = emailPattern.ReplaceAllString(body, RedactedPlaceholder)
+
+	if r.maxBodyBytes > 0 && len(body) > r.maxBodyBytes {
+		body = body[:r.maxBodyBytes] + "...(truncated)"
+	}
+	return body
+}
+
+// redactJSONPaths blanks out the configured JSONPath-like expressions if body parses as JSON. Any body that
+// is not valid JSON is returned unchanged.
+func (r *Redactor) redactJSONPaths(body string) string {
+	if len(r.jsonPaths) == 0 {
+		return body
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return body
+	}
+
+	for _, path := range r.jsonPaths {
+		redactJSONPath(parsed, path)
+	}
+
+	redacted, err := json.Marshal(parsed)
+	if err != nil {
+		return body
+	}
+	return string(redacted)
+}
+
+// redactJSONPath walks a small subset of JSONPath ("$.a.b.c") and replaces the leaf value in place with
+// RedactedPlaceholder, as long as every intermediate node is a JSON object
+func redactJSONPath(node interface{}, path string) {
+	segments := strings.Split(strings.TrimPrefix(path, "$."), ".")
+
+	current := node
+	for i, segment := range segments {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		if i == len(segments)-1 {
+			if _, exists := obj[segment]; exists {
+				obj[segment] = RedactedPlaceholder
+			}
+			return
+		}
+
+		current, ok = obj[segment]
+		if !ok {
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package xablogger
+
+import (
+	"net/http"
+	"testing"
+)
+
+func benchRedactor() *Redactor {
+	r := newRedactor()
+	r.headers["authorization"] = struct{}{}
+	r.headers["cookie"] = struct{}{}
+	r.jsonPaths = []string{"$.password", "$.card.number"}
+	r.sqlParams["password"] = struct{}{}
+	r.maxBodyBytes = 4096
+	return r
+}
+
+func BenchmarkRedactorHeaders(b *testing.B) {
+	r := benchRedactor()
+	headers := http.Header{
+		"Authorization": []string{"Bearer token"},
+		"Cookie":        []string{"session=abc"},
+		"Content-Type":  []string{"application/json"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Headers(headers)
+	}
+}
+
+func BenchmarkRedactorSQLParams(b *testing.B) {
+	r := benchRedactor()
+	params := map[string]interface{}{
+		"username": "jane",
+		"password": "hunter2",
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.SQLParams(params)
+	}
+}
+
+func BenchmarkRedactorBody(b *testing.B) {
+	r := benchRedactor()
+	body := `{"username":"jane","password":"hunter2","email":"jane@example.com","card":{"number":"4111111111111111"}}`
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Body(body)
+	}
+}
@@ -0,0 +1,141 @@
+package xablogger
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func testRedactor() *Redactor {
+	r := newRedactor()
+	r.headers["authorization"] = struct{}{}
+	r.headers["cookie"] = struct{}{}
+	r.jsonPaths = []string{"$.password", "$.card.number"}
+	r.sqlParams["password"] = struct{}{}
+	return r
+}
+
+func TestRedactorHeaders(t *testing.T) {
+	r := testRedactor()
+	headers := http.Header{
+		"Authorization": []string{"Bearer token"},
+		"Cookie":        []string{"session=abc"},
+		"Content-Type":  []string{"application/json"},
+	}
+
+	redacted := r.Headers(headers)
+
+	if got := redacted.Get("Authorization"); got != RedactedPlaceholder {
+		t.Errorf("Authorization = %q, want %q", got, RedactedPlaceholder)
+	}
+	if got := redacted.Get("Cookie"); got != RedactedPlaceholder {
+		t.Errorf("Cookie = %q, want %q", got, RedactedPlaceholder)
+	}
+	if got := redacted.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want unchanged", got)
+	}
+	if headers.Get("Authorization") != "Bearer token" {
+		t.Error("Headers mutated the original http.Header instead of returning a copy")
+	}
+}
+
+func TestRedactorHeadersNilRedactor(t *testing.T) {
+	var r *Redactor
+	headers := http.Header{"Authorization": []string{"Bearer token"}}
+
+	if got := r.Headers(headers); got.Get("Authorization") != "Bearer token" {
+		t.Errorf("nil Redactor should pass headers through unchanged, got %q", got.Get("Authorization"))
+	}
+}
+
+func TestRedactorSQLParams(t *testing.T) {
+	r := testRedactor()
+	params := map[string]interface{}{
+		"username": "jane",
+		"password": "hunter2",
+	}
+
+	redacted := r.SQLParams(params)
+
+	if redacted["password"] != RedactedPlaceholder {
+		t.Errorf("password = %v, want %q", redacted["password"], RedactedPlaceholder)
+	}
+	if redacted["username"] != "jane" {
+		t.Errorf("username = %v, want unchanged", redacted["username"])
+	}
+	if params["password"] != "hunter2" {
+		t.Error("SQLParams mutated the original map instead of returning a copy")
+	}
+}
+
+func TestRedactorBodyJSONPaths(t *testing.T) {
+	r := testRedactor()
+	body := `{"username":"jane","password":"hunter2","card":{"number":"not-a-card-shape"}}`
+
+	redacted := r.Body(body)
+
+	if want := `"password":"` + RedactedPlaceholder + `"`; !strings.Contains(redacted, want) {
+		t.Errorf("Body() = %q, want it to contain %q", redacted, want)
+	}
+	if want := `"number":"` + RedactedPlaceholder + `"`; !strings.Contains(redacted, want) {
+		t.Errorf("Body() = %q, want it to contain %q", redacted, want)
+	}
+	if !strings.Contains(redacted, `"username":"jane"`) {
+		t.Errorf("Body() = %q, want username left unchanged", redacted)
+	}
+}
+
+func TestRedactorBodyBuiltinPatterns(t *testing.T) {
+	r := newRedactor()
+	body := `contact jane@example.com about card 4111111111111111`
+
+	redacted := r.Body(body)
+
+	if strings.Contains(redacted, "jane@example.com") {
+		t.Errorf("Body() = %q, want email redacted", redacted)
+	}
+	if strings.Contains(redacted, "4111111111111111") {
+		t.Errorf("Body() = %q, want credit card number redacted", redacted)
+	}
+}
+
+func TestRedactorBodyTruncation(t *testing.T) {
+	r := newRedactor()
+	r.maxBodyBytes = 8
+	body := "0123456789"
+
+	redacted := r.Body(body)
+
+	if !strings.Contains(redacted, "...(truncated)") {
+		t.Errorf("Body() = %q, want truncation marker", redacted)
+	}
+	if len(redacted) >= len(body)+len("...(truncated)") {
+		t.Errorf("Body() = %q, want it shorter than the untruncated input plus marker", redacted)
+	}
+}
+
+func TestRedactorBodyNotJSON(t *testing.T) {
+	r := testRedactor()
+	body := "not json at all"
+
+	if got := r.Body(body); got != body {
+		t.Errorf("Body() = %q, want non-JSON body returned unchanged (aside from pattern redaction)", got)
+	}
+}
+
+func TestRedactorMaxBodyBytes(t *testing.T) {
+	r := newRedactor()
+	if got := r.MaxBodyBytes(); got != 0 {
+		t.Errorf("MaxBodyBytes() = %d, want 0 when unconfigured", got)
+	}
+
+	r.maxBodyBytes = 4096
+	if got := r.MaxBodyBytes(); got != 4096 {
+		t.Errorf("MaxBodyBytes() = %d, want 4096", got)
+	}
+
+	var nilRedactor *Redactor
+	if got := nilRedactor.MaxBodyBytes(); got != 0 {
+		t.Errorf("MaxBodyBytes() on nil Redactor = %d, want 0", got)
+	}
+}
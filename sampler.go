@@ -0,0 +1,150 @@
+package xablogger
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Sampler decides whether a transaction, and every segment appended to it, should have its entries
+// delivered to the sink pipeline. The decision is made once per transaction, when it is created, and cached;
+// segments appended afterwards inherit it. A transaction that starts out sampled-out can still be upgraded
+// to sampled-in the moment one of its segments fails, giving samplers like ErrorPrioritySampler a chance to
+// keep failing transactions regardless of the original decision.
+type Sampler interface {
+	// Sample returns true if the transaction identified by transactionID should be kept. failed reports
+	// whether the transaction or the segment currently being evaluated has failed.
+	Sample(transactionID string, failed bool) bool
+}
+
+// alwaysSampler keeps every transaction; it is the default sampler when Init is called without Sampling
+type alwaysSampler struct{}
+
+func (alwaysSampler) Sample(string, bool) bool { return true }
+
+// RateLimitSampler keeps up to perSec transactions per second using a token bucket, dropping the rest. Use
+// it to put a hard ceiling on log volume regardless of traffic spikes.
+type RateLimitSampler struct {
+	mux        sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	refillRate float64
+	lastRefill time.Time
+}
+
+// NewRateLimitSampler builds a RateLimitSampler that keeps at most perSec transactions per second
+func NewRateLimitSampler(perSec int) *RateLimitSampler {
+	return &RateLimitSampler{
+		tokens:     float64(perSec),
+		maxTokens:  float64(perSec),
+		refillRate: float64(perSec),
+		lastRefill: time.Now(),
+	}
+}
+
+// Sample consumes one token if available, refilling the bucket based on elapsed time since the last call
+func (s *RateLimitSampler) Sample(_ string, _ bool) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := time.Now()
+	s.tokens += now.Sub(s.lastRefill).Seconds() * s.refillRate
+	if s.tokens > s.maxTokens {
+		s.tokens = s.maxTokens
+	}
+	s.lastRefill = now
+
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+// ProbabilitySampler keeps a fixed fraction p (between 0.0 and 1.0) of transactions, chosen at random
+type ProbabilitySampler struct {
+	p float64
+}
+
+// NewProbabilitySampler builds a ProbabilitySampler that keeps a fraction p of transactions
+func NewProbabilitySampler(p float64) *ProbabilitySampler {
+	return &ProbabilitySampler{p: p}
+}
+
+// Sample returns true with probability p
+func (s *ProbabilitySampler) Sample(_ string, _ bool) bool {
+	return rand.Float64() < s.p
+}
+
+// AdaptiveSampler adjusts its sampling probability once per adjustment window to keep throughput close to
+// targetQPS, similar to Jaeger's remote sampler. It starts at probability 1.0 and backs off once observed
+// throughput exceeds the target.
+type AdaptiveSampler struct {
+	targetQPS      float64
+	adjustInterval time.Duration
+
+	mux         sync.Mutex
+	probability float64
+	windowStart time.Time
+	windowCount int
+}
+
+// NewAdaptiveSampler builds an AdaptiveSampler targeting targetQPS sampled transactions per second
+func NewAdaptiveSampler(targetQPS float64) *AdaptiveSampler {
+	return &AdaptiveSampler{
+		targetQPS:      targetQPS,
+		adjustInterval: time.Second,
+		probability:    1,
+		windowStart:    time.Now(),
+	}
+}
+
+// Sample probabilistically keeps the transaction, recalculating the probability once per adjustInterval
+// based on how many transactions were seen in the previous window versus targetQPS
+func (s *AdaptiveSampler) Sample(_ string, _ bool) bool {
+	s.mux.Lock()
+
+	now := time.Now()
+	if elapsed := now.Sub(s.windowStart); elapsed >= s.adjustInterval {
+		if observedQPS := float64(s.windowCount) / elapsed.Seconds(); observedQPS > 0 {
+			s.probability = clampProbability(s.targetQPS / observedQPS)
+		}
+		s.windowStart = now
+		s.windowCount = 0
+	}
+	s.windowCount++
+	probability := s.probability
+
+	s.mux.Unlock()
+
+	return rand.Float64() < probability
+}
+
+func clampProbability(p float64) float64 {
+	if p < 0 {
+		return 0
+	}
+	if p > 1 {
+		return 1
+	}
+	return p
+}
+
+// ErrorPrioritySampler always keeps failed transactions/segments, and samples successes through inner.
+type ErrorPrioritySampler struct {
+	inner Sampler
+}
+
+// NewErrorPrioritySampler builds an ErrorPrioritySampler that always keeps failures and delegates sampling
+// of successes to inner
+func NewErrorPrioritySampler(inner Sampler) *ErrorPrioritySampler {
+	return &ErrorPrioritySampler{inner: inner}
+}
+
+// Sample returns true unconditionally when failed is true, otherwise defers to inner
+func (s *ErrorPrioritySampler) Sample(transactionID string, failed bool) bool {
+	if failed {
+		return true
+	}
+	return s.inner.Sample(transactionID, failed)
+}
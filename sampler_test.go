@@ -0,0 +1,140 @@
+package xablogger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitSamplerCapsPerSecond(t *testing.T) {
+	s := NewRateLimitSampler(3)
+
+	kept := 0
+	for i := 0; i < 10; i++ {
+		if s.Sample("tx", false) {
+			kept++
+		}
+	}
+
+	if kept != 3 {
+		t.Errorf("kept %d of 10 back-to-back calls, want exactly 3 (the bucket's starting capacity)", kept)
+	}
+}
+
+func TestRateLimitSamplerRefills(t *testing.T) {
+	s := NewRateLimitSampler(10)
+	for i := 0; i < 10; i++ {
+		s.Sample("tx", false)
+	}
+	if s.Sample("tx", false) {
+		t.Fatal("bucket should be empty immediately after draining it")
+	}
+
+	s.lastRefill = time.Now().Add(-time.Second)
+	if !s.Sample("tx", false) {
+		t.Error("bucket should have refilled after a full second elapsed")
+	}
+}
+
+func TestProbabilitySamplerBounds(t *testing.T) {
+	always := NewProbabilitySampler(1)
+	for i := 0; i < 100; i++ {
+		if !always.Sample("tx", false) {
+			t.Fatal("p=1 sampler dropped a transaction")
+		}
+	}
+
+	never := NewProbabilitySampler(0)
+	for i := 0; i < 100; i++ {
+		if never.Sample("tx", false) {
+			t.Fatal("p=0 sampler kept a transaction")
+		}
+	}
+}
+
+func TestProbabilitySamplerConvergesToP(t *testing.T) {
+	s := NewProbabilitySampler(0.5)
+
+	const n = 20000
+	kept := 0
+	for i := 0; i < n; i++ {
+		if s.Sample("tx", false) {
+			kept++
+		}
+	}
+
+	got := float64(kept) / n
+	if got < 0.45 || got > 0.55 {
+		t.Errorf("observed keep rate %.3f over %d samples, want close to 0.5", got, n)
+	}
+}
+
+func TestClampProbability(t *testing.T) {
+	cases := map[float64]float64{
+		-1:  0,
+		0:   0,
+		0.5: 0.5,
+		1:   1,
+		2:   1,
+	}
+	for in, want := range cases {
+		if got := clampProbability(in); got != want {
+			t.Errorf("clampProbability(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestAdaptiveSamplerBacksOffAboveTarget(t *testing.T) {
+	s := NewAdaptiveSampler(10)
+	s.adjustInterval = time.Millisecond
+
+	// burn through one window well above the target QPS so the next window recalculates probability down
+	for i := 0; i < 1000; i++ {
+		s.Sample("tx", false)
+	}
+	time.Sleep(2 * time.Millisecond)
+	s.Sample("tx", false) // triggers the recalculation for the window that just elapsed
+
+	s.mux.Lock()
+	probability := s.probability
+	s.mux.Unlock()
+
+	if probability >= 1 {
+		t.Errorf("probability = %v, want it to have backed off below 1 after a window far exceeding targetQPS", probability)
+	}
+}
+
+func TestAdaptiveSamplerProbabilityStaysInBounds(t *testing.T) {
+	s := NewAdaptiveSampler(10)
+	s.adjustInterval = time.Millisecond
+
+	for i := 0; i < 5000; i++ {
+		s.Sample("tx", false)
+		if i%50 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.probability < 0 || s.probability > 1 {
+		t.Errorf("probability = %v, want it within [0, 1]", s.probability)
+	}
+}
+
+func TestErrorPrioritySamplerAlwaysKeepsFailures(t *testing.T) {
+	s := NewErrorPrioritySampler(NewProbabilitySampler(0))
+
+	if !s.Sample("tx", true) {
+		t.Error("failed=true should always be kept regardless of the inner sampler")
+	}
+	if s.Sample("tx", false) {
+		t.Error("failed=false should defer to the inner sampler, which never keeps")
+	}
+}
+
+func TestAlwaysSamplerKeepsEverything(t *testing.T) {
+	s := alwaysSampler{}
+	if !s.Sample("tx", false) || !s.Sample("tx", true) {
+		t.Error("alwaysSampler should keep every transaction regardless of failed")
+	}
+}
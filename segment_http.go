@@ -2,15 +2,21 @@ package xablogger
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPSegment is used to generate log entries from HTTP transactions. Use this segment for generating log metrics
 // for incoming requests for your REST API or when invoking external APIs via HTTP clients
 type HTTPSegment struct {
+	ctx   context.Context
+	span  trace.Span
 	start time.Time
 	data  map[string]interface{}
 	mux   sync.Mutex
@@ -19,25 +25,34 @@ type HTTPSegment struct {
 // NewServerSegment initialized a ServerSegment instance. It will set the current timestamp in the segment start data and latency
 // will be computed from the function return until Done function is called.
 // It will also use the body contents and set an identifical copy on the request object.
-func NewServerSegment(r *http.Request) *HTTPSegment {
+// ctx is used as the parent for this segment's span; pass the context returned by NewTransactionWithContext
+// (or whatever context carries the caller's span) so the segment links up with the rest of the trace.
+func NewServerSegment(ctx context.Context, r *http.Request) *HTTPSegment {
 	// init data map with all default fields
+	redactor := activeRedactor()
+
 	data := map[string]interface{}{
 		"method":               r.Method,
 		"path":                 r.URL.Path,
 		"request.query_params": r.URL.Query,
-		"request.headers":      r.Header,
+		"request.headers":      redactor.Headers(r.Header),
 	}
 
 	// if the request has body, we duplicate the buffer so that we can log the body contents and
-	// keep the request unmodified
-	if r.Body != http.NoBody {
+	// keep the request unmodified. r.Body is nil (not http.NoBody) for a request built with
+	// http.NewRequest(method, url, nil), the common bodyless-GET case for an outbound client request.
+	if r.Body != nil && r.Body != http.NoBody {
 		buf, _ := ioutil.ReadAll(r.Body)
 		// sets the copied buffer on the request
 		r.Body = ioutil.NopCloser(bytes.NewBuffer(buf))
-		data["request.body"] = string(buf)
+		data["request.body"] = redactor.Body(string(buf))
 	}
 
+	spanCtx, span := startSegmentSpan(ctx, "http.client "+r.Method+" "+r.URL.Path)
+
 	return &HTTPSegment{
+		ctx:   spanCtx,
+		span:  span,
 		start: time.Now(),
 		data:  data,
 	}
@@ -48,6 +63,17 @@ func (s *HTTPSegment) Type() string {
 	return "http"
 }
 
+// Context returns the context.Context carrying this segment's span
+func (s *HTTPSegment) Context() context.Context {
+	return s.ctx
+}
+
+// InjectHeaders writes the segment's W3C traceparent/tracestate headers into req, so that whichever server
+// handles req can be linked back to this span. Call this before passing req to an http.Client's Do method.
+func (s *HTTPSegment) InjectHeaders(req *http.Request) {
+	injectTraceHeaders(s.ctx, propagation.HeaderCarrier(req.Header))
+}
+
 // Failed marks that an error has ocurred on this segment. It will also set an 'status_code' key with
 // internal server error (500) status code
 func (s *HTTPSegment) Failed(err error) {
@@ -70,24 +96,27 @@ func (s *HTTPSegment) HasFailed() bool {
 // Response fills the keys for response data.
 // It will also use the body contents and set an identifical copy on the response object.
 func (s *HTTPSegment) Response(res *http.Response) {
+	redactor := activeRedactor()
+
 	s.mux.Lock()
 	s.data["status_code"] = res.StatusCode
-	s.data["response.headers"] = res.Header
+	s.data["response.headers"] = redactor.Headers(res.Header)
 
 	// if the response has body, we duplicate the buffer so that we can log the body contents and
 	// keep the response unmodified
-	if res.Body != http.NoBody {
+	if res.Body != nil && res.Body != http.NoBody {
 		buf, _ := ioutil.ReadAll(res.Body)
 		// sets the copied buffer on the request
 		res.Body = ioutil.NopCloser(bytes.NewBuffer(buf))
-		s.data["response.body"] = string(buf)
+		s.data["response.body"] = redactor.Body(string(buf))
 	}
 
 	s.mux.Unlock()
 }
 
 // Done stops measuring elapsed time. If the data map does not contains a 'status_code' key, it will set
-// the OK (200) status code
+// the OK (200) status code. It also ends the segment's OpenTelemetry span, attaching the segment's fields
+// as span attributes.
 func (s *HTTPSegment) Done() {
 	s.mux.Lock()
 	s.data["elapsed_ms"] = int(time.Since(s.start) / time.Millisecond)
@@ -97,5 +126,6 @@ func (s *HTTPSegment) Done() {
 	}
 
 	s.mux.Unlock()
-	return
+
+	endSegmentSpan(s.span, s)
 }
@@ -1,5 +1,7 @@
 package xablogger
 
+import "context"
+
 // Segment is the interface that all metric types must implement.
 type Segment interface {
 	Type() string
@@ -7,4 +9,8 @@ type Segment interface {
 	Fields() map[string]interface{}
 	HasFailed() bool
 	Done()
+
+	// Context returns the context.Context carrying this segment's span, so callers can derive further
+	// child spans/segments without re-discovering the parent transaction
+	Context() context.Context
 }
@@ -1,13 +1,18 @@
 package xablogger
 
 import (
+	"context"
 	"database/sql"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // SQLSegment is used to generate log entries from SQL transactions.
 type SQLSegment struct {
+	ctx   context.Context
+	span  trace.Span
 	start time.Time
 	data  map[string]interface{}
 	mux   sync.Mutex
@@ -15,12 +20,18 @@ type SQLSegment struct {
 
 // NewSQLSegment initializes a SQLSegment instance. It will set the current timestamp in the segment start data and latency
 // will be computed from the function return until Done function is called.
-func NewSQLSegment(driver string, statement string, params map[string]interface{}) *SQLSegment {
+// ctx is used as the parent for this segment's span; pass the context carrying the calling segment/transaction
+// so the query can be linked back to the rest of the trace.
+func NewSQLSegment(ctx context.Context, driver string, statement string, params map[string]interface{}) *SQLSegment {
+	spanCtx, span := startSegmentSpan(ctx, "sql "+driver)
+
 	return &SQLSegment{
+		ctx:   spanCtx,
+		span:  span,
 		start: time.Now(),
 		data: map[string]interface{}{
 			"statement": statement,
-			"params":    params,
+			"params":    activeRedactor().SQLParams(params),
 			"driver":    driver,
 		},
 	}
@@ -31,6 +42,11 @@ func (s *SQLSegment) Type() string {
 	return "sql"
 }
 
+// Context returns the context.Context carrying this segment's span
+func (s *SQLSegment) Context() context.Context {
+	return s.ctx
+}
+
 // Failed marks that an error has ocurred on this segment.
 func (s *SQLSegment) Failed(err error) {
 	s.mux.Lock()
@@ -69,10 +85,12 @@ func (s *SQLSegment) QueryResponse(rows *sql.Rows) {
 	s.mux.Unlock()
 }
 
-// Done stops measuring elapsed time
+// Done stops measuring elapsed time. It also ends the segment's OpenTelemetry span, attaching the segment's
+// fields as span attributes.
 func (s *SQLSegment) Done() {
 	s.mux.Lock()
 	s.data["elapsed_ms"] = int(time.Since(s.start) / time.Millisecond)
 	s.mux.Unlock()
-	return
+
+	endSegmentSpan(s.span, s)
 }
@@ -2,41 +2,68 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/andretanaka/xablogger"
 )
 
+// tracer is the OpenTelemetry tracer used to create server segment spans
+var tracer = otel.Tracer("github.com/andretanaka/xablogger/segments/http")
+
+// propagator handles decoding of the W3C traceparent/tracestate headers found on inbound requests
+var propagator = propagation.TraceContext{}
+
 // ServerSegment is used to generate log entries from server perspective. Use this segment when generating metrics
 // for your APIs
 type ServerSegment struct {
+	ctx   context.Context
+	span  trace.Span
 	start time.Time
 	data  map[string]interface{}
 	mux   sync.Mutex
 }
 
-// NewServerSegment initialized a ServerSegment instance. It will set the current timestamp in the segment
-func NewServerSegment(r *http.Request) *ServerSegment {
+// NewServerSegment initialized a ServerSegment instance. It will set the current timestamp in the segment.
+// It extracts any W3C traceparent/tracestate headers present on r and uses them as the parent span, so the
+// segment is linked back to whichever caller (e.g. an xablogger HTTPSegment) originated the request.
+func NewServerSegment(ctx context.Context, r *http.Request) *ServerSegment {
+	redactor := xablogger.ActiveRedactor()
+
 	// init data map with all default fields
 	data := map[string]interface{}{
 		"method":               r.Method,
 		"path":                 r.URL.Path,
 		"request.query_params": r.URL.Query,
-		"request.headers":      r.Header,
+		"request.headers":      redactor.Headers(r.Header),
 	}
 
 	// if the request has body, we duplicate the buffer so that we can log the body contents and
-	// keep the request unmodified
-	if r.Body != http.NoBody {
+	// keep the request unmodified. net/http guarantees a non-nil Body on server-side requests, but we guard
+	// against nil here too, matching the same check in xablogger.NewServerSegment/Response.
+	if r.Body != nil && r.Body != http.NoBody {
 		buf, _ := ioutil.ReadAll(r.Body)
 		// sets the copied buffer on the request
 		r.Body = ioutil.NopCloser(bytes.NewBuffer(buf))
-		data["request.body"] = string(buf)
+		data["request.body"] = redactor.Body(string(buf))
 	}
 
+	parentCtx := propagator.Extract(ctx, propagation.HeaderCarrier(r.Header))
+	spanCtx, span := tracer.Start(parentCtx, "http.server "+r.Method+" "+r.URL.Path)
+
 	return &ServerSegment{
+		ctx:   spanCtx,
+		span:  span,
 		start: time.Now(),
 		data:  data,
 	}
@@ -47,6 +74,12 @@ func (s *ServerSegment) Type() string {
 	return "http - server"
 }
 
+// Context returns the context.Context carrying this segment's span, extended with the remote parent span
+// extracted from the inbound request, if any
+func (s *ServerSegment) Context() context.Context {
+	return s.ctx
+}
+
 // Failed marks that an error has ocurred on this segment. It will also set an 'status_code' key with
 // internal server error (500) status code
 func (s *ServerSegment) Failed(err error) {
@@ -69,21 +102,38 @@ func (s *ServerSegment) HasFailed() bool {
 // JSONResponse sets data for a JSON response. If an error occurs marshalling the body, the 'response_body' key
 // will not be set
 func (s *ServerSegment) JSONResponse(statusCode int, body interface{}, headers http.Header) {
+	redactor := xablogger.ActiveRedactor()
+
 	s.mux.Lock()
 
 	s.data["status_code"] = statusCode
-	s.data["response.headers"] = headers
+	s.data["response.headers"] = redactor.Headers(headers)
 
 	if body != nil {
 		if responseBytes, err := json.Marshal(body); err == nil {
-			s.data["response.body"] = string(responseBytes)
+			s.data["response.body"] = redactor.Body(string(responseBytes))
 		}
 	}
 	s.mux.Unlock()
 }
 
+// RawResponse sets data for a response whose body was already serialized by the caller, e.g. a
+// middleware package's responseRecorder capturing whatever bytes the handler wrote.
+func (s *ServerSegment) RawResponse(statusCode int, headers http.Header, body []byte) {
+	redactor := xablogger.ActiveRedactor()
+
+	s.mux.Lock()
+	s.data["status_code"] = statusCode
+	s.data["response.headers"] = redactor.Headers(headers)
+	if len(body) > 0 {
+		s.data["response.body"] = redactor.Body(string(body))
+	}
+	s.mux.Unlock()
+}
+
 // Done stops measuring elapsed time. If the data map does not contains a 'status_code' key, it will set
-// the OK (200) status code
+// the OK (200) status code. It also ends the segment's OpenTelemetry span, attaching the segment's fields
+// as span attributes.
 func (s *ServerSegment) Done() {
 	s.mux.Lock()
 	s.data["elapsed_ms"] = int(time.Since(s.start) / time.Millisecond)
@@ -92,6 +142,14 @@ func (s *ServerSegment) Done() {
 		s.data["status_code"] = http.StatusOK
 	}
 
+	for k, v := range s.data {
+		s.span.SetAttributes(attribute.String(k, fmt.Sprintf("%v", v)))
+	}
+	if s.data["error"] != nil {
+		s.span.RecordError(fmt.Errorf("%v", s.data["error"]))
+	}
+
 	s.mux.Unlock()
-	return
+
+	s.span.End()
 }
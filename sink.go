@@ -0,0 +1,33 @@
+package xablogger
+
+import "time"
+
+// Entry is the unit of data handed to a Sink. It carries the same information that used to be logged
+// directly through logrus, decoupled from any particular backend.
+type Entry struct {
+	// TransactionID identifies the transaction this entry belongs to
+	TransactionID string
+	// SegmentType is the segment's Type(), empty for a transaction's audit entry
+	SegmentType string
+	// Audit is true for the single entry emitted by FlushTransaction, false for per-segment entries
+	Audit bool
+	// Failed mirrors Segment.HasFailed() for segment entries. For the audit entry, it reports whether any
+	// segment appended to the transaction failed.
+	Failed bool
+	// Fields holds the segment's Fields() for segment entries. For the audit entry, it holds every
+	// appended segment's Fields() merged together in append order, so a later segment's keys win on conflict.
+	Fields map[string]interface{}
+	// Timestamp is when the entry was produced
+	Timestamp time.Time
+}
+
+// Sink receives entries produced by segments and transactions. Implementations must be safe for concurrent
+// use, since entries are delivered by the sink pipeline's worker goroutines.
+type Sink interface {
+	// Write persists a single entry
+	Write(entry Entry) error
+	// Flush forces any buffered entries to be persisted immediately
+	Flush() error
+	// Close releases any resources held by the sink. It is called once, during Shutdown
+	Close() error
+}
@@ -0,0 +1,51 @@
+package xablogger
+
+import "github.com/sirupsen/logrus"
+
+// LogrusSink forwards entries to a logrus.Logger, reproducing the format xablogger used before the sink
+// pipeline existed. It is the default sink when Init is called without Sinks.
+type LogrusSink struct {
+	Logger *logrus.Logger
+	Fields map[string]interface{}
+}
+
+// NewLogrusSink builds a LogrusSink that writes to logger, merging fields into every entry.
+func NewLogrusSink(logger *logrus.Logger, fields map[string]interface{}) *LogrusSink {
+	return &LogrusSink{Logger: logger, Fields: fields}
+}
+
+// Write logs entry through the underlying logrus.Logger
+func (s *LogrusSink) Write(entry Entry) error {
+	logFields := make(map[string]interface{}, len(s.Fields)+2)
+	for k, v := range s.Fields {
+		logFields[k] = v
+	}
+	logFields["audit"] = entry.Audit
+
+	if entry.Audit {
+		for k, v := range entry.Fields {
+			logFields[k] = v
+		}
+	} else {
+		logFields["segment.type"] = entry.SegmentType
+		logFields["segment.data"] = entry.Fields
+	}
+
+	logEntry := s.Logger.WithFields(logFields)
+	if entry.Failed {
+		logEntry.Error()
+	} else {
+		logEntry.Info()
+	}
+	return nil
+}
+
+// Flush is a no-op: logrus writes synchronously on every call
+func (s *LogrusSink) Flush() error {
+	return nil
+}
+
+// Close is a no-op: the underlying logrus.Logger owns no resources xablogger needs to release
+func (s *LogrusSink) Close() error {
+	return nil
+}
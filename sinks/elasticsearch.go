@@ -0,0 +1,100 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// Elasticsearch is a Sink that ships entries to an Elasticsearch (or OpenSearch) cluster using the bulk API.
+// Entries are buffered and sent in batches of BatchSize, or whenever Flush is called.
+type Elasticsearch struct {
+	// URL is the cluster's base URL, e.g. "http://localhost:9200"
+	URL string
+	// Index is the target index name
+	Index string
+	// BatchSize is how many entries accumulate before an automatic bulk request. Defaults to 100.
+	BatchSize int
+	// Client is the http.Client used to talk to the cluster. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	mux    sync.Mutex
+	buffer []xablogger.Entry
+}
+
+// NewElasticsearch builds an Elasticsearch sink targeting url/index
+func NewElasticsearch(url, index string) *Elasticsearch {
+	return &Elasticsearch{
+		URL:       url,
+		Index:     index,
+		BatchSize: 100,
+		Client:    http.DefaultClient,
+	}
+}
+
+// Write buffers entry, flushing automatically once BatchSize entries have accumulated
+func (s *Elasticsearch) Write(entry xablogger.Entry) error {
+	s.mux.Lock()
+	s.buffer = append(s.buffer, entry)
+	full := len(s.buffer) >= s.BatchSize
+	s.mux.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush sends every buffered entry to the cluster's _bulk endpoint in a single request
+func (s *Elasticsearch) Flush() error {
+	s.mux.Lock()
+	pending := s.buffer
+	s.buffer = nil
+	s.mux.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	for _, entry := range pending {
+		action := map[string]interface{}{"index": map[string]interface{}{"_index": s.Index}}
+		if err := json.NewEncoder(&body).Encode(action); err != nil {
+			return err
+		}
+		if err := json.NewEncoder(&body).Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/_bulk", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("xablogger: elasticsearch bulk request failed with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// Close flushes any remaining buffered entries
+func (s *Elasticsearch) Close() error {
+	return s.Flush()
+}
@@ -0,0 +1,138 @@
+package sinks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// capturingServer records every request body (and how many requests arrived) sent to it.
+type capturingServer struct {
+	mux    sync.Mutex
+	bodies [][]byte
+	status int
+	server *httptest.Server
+}
+
+func newCapturingServer(status int) *capturingServer {
+	s := &capturingServer{status: status}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mux.Lock()
+		s.bodies = append(s.bodies, body)
+		s.mux.Unlock()
+		w.WriteHeader(s.status)
+	}))
+	return s
+}
+
+func (s *capturingServer) requestCount() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.bodies)
+}
+
+func (s *capturingServer) lastBody() []byte {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.bodies[len(s.bodies)-1]
+}
+
+func (s *capturingServer) close() { s.server.Close() }
+
+func TestElasticsearchWriteBatchesUntilBatchSize(t *testing.T) {
+	server := newCapturingServer(http.StatusOK)
+	defer server.close()
+
+	es := NewElasticsearch(server.server.URL, "logs")
+	es.BatchSize = 2
+
+	if err := es.Write(xablogger.Entry{TransactionID: "tx1"}); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if n := server.requestCount(); n != 0 {
+		t.Fatalf("requestCount() = %d, want 0 before BatchSize is reached", n)
+	}
+
+	if err := es.Write(xablogger.Entry{TransactionID: "tx2"}); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if n := server.requestCount(); n != 1 {
+		t.Fatalf("requestCount() = %d, want 1 once BatchSize is reached", n)
+	}
+
+	lines := bufio.NewScanner(bytes.NewReader(server.lastBody()))
+	var decoded []map[string]interface{}
+	for lines.Scan() {
+		var m map[string]interface{}
+		if err := json.Unmarshal(lines.Bytes(), &m); err != nil {
+			t.Fatalf("decode bulk line: %v", err)
+		}
+		decoded = append(decoded, m)
+	}
+	if len(decoded) != 4 {
+		t.Fatalf("bulk body has %d NDJSON lines, want 4 (action+doc per entry)", len(decoded))
+	}
+	if _, ok := decoded[0]["index"]; !ok {
+		t.Errorf("first line = %v, want an {\"index\": ...} action line", decoded[0])
+	}
+	if decoded[1]["TransactionID"] != "tx1" {
+		t.Errorf("second line TransactionID = %v, want %q", decoded[1]["TransactionID"], "tx1")
+	}
+}
+
+func TestElasticsearchFlushSendsBufferedEntries(t *testing.T) {
+	server := newCapturingServer(http.StatusOK)
+	defer server.close()
+
+	es := NewElasticsearch(server.server.URL, "logs")
+
+	if err := es.Write(xablogger.Entry{TransactionID: "tx1"}); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if n := server.requestCount(); n != 0 {
+		t.Fatalf("requestCount() = %d, want 0 before Flush", n)
+	}
+
+	if err := es.Flush(); err != nil {
+		t.Fatalf("Flush() = %v", err)
+	}
+	if n := server.requestCount(); n != 1 {
+		t.Fatalf("requestCount() = %d, want 1 after Flush", n)
+	}
+
+	// the buffer is reset before the request is sent, so a second Flush with nothing new buffered is a no-op
+	if err := es.Flush(); err != nil {
+		t.Fatalf("second Flush() = %v", err)
+	}
+	if n := server.requestCount(); n != 1 {
+		t.Errorf("requestCount() = %d, want still 1 after a Flush with an empty buffer", n)
+	}
+}
+
+func TestElasticsearchFlushDropsBatchOnFailedPost(t *testing.T) {
+	server := newCapturingServer(http.StatusInternalServerError)
+	defer server.close()
+
+	es := NewElasticsearch(server.server.URL, "logs")
+	_ = es.Write(xablogger.Entry{TransactionID: "tx1"})
+
+	if err := es.Flush(); err == nil {
+		t.Fatal("Flush() = nil, want an error on a 500 response")
+	}
+
+	// the buffer was reset before the POST, so the failed batch is gone: a later Flush sends nothing more
+	if err := es.Flush(); err != nil {
+		t.Fatalf("second Flush() = %v, want nil (buffer already drained)", err)
+	}
+	if n := server.requestCount(); n != 1 {
+		t.Errorf("requestCount() = %d, want 1 (the dropped batch was never resent)", n)
+	}
+}
@@ -0,0 +1,45 @@
+package sinks
+
+import (
+	"encoding/json"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// KafkaProducer is the subset of a Kafka client xablogger needs to publish entries. Most Kafka client
+// libraries (sarama, confluent-kafka-go, segmentio/kafka-go) can satisfy this with a small adapter, which
+// keeps this package free of a hard dependency on any one of them.
+type KafkaProducer interface {
+	Produce(topic string, key, value []byte) error
+}
+
+// Kafka is a Sink that publishes each entry as a JSON-encoded message to a Kafka topic.
+type Kafka struct {
+	Producer KafkaProducer
+	Topic    string
+}
+
+// NewKafka builds a Kafka sink publishing to topic through producer
+func NewKafka(producer KafkaProducer, topic string) *Kafka {
+	return &Kafka{Producer: producer, Topic: topic}
+}
+
+// Write JSON-encodes entry and publishes it, keyed by TransactionID so a given transaction's entries land
+// on the same partition
+func (s *Kafka) Write(entry xablogger.Entry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.Producer.Produce(s.Topic, []byte(entry.TransactionID), value)
+}
+
+// Flush is a no-op: batching/acking is left to the underlying KafkaProducer implementation
+func (s *Kafka) Flush() error {
+	return nil
+}
+
+// Close is a no-op: xablogger does not own the KafkaProducer's lifecycle
+func (s *Kafka) Close() error {
+	return nil
+}
@@ -0,0 +1,62 @@
+package sinks
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// recordingProducer is a KafkaProducer test double that records every Produce call.
+type recordingProducer struct {
+	topic string
+	key   []byte
+	value []byte
+	err   error
+}
+
+func (p *recordingProducer) Produce(topic string, key, value []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.topic = topic
+	p.key = key
+	p.value = value
+	return nil
+}
+
+func TestKafkaWritePublishesJSONKeyedByTransactionID(t *testing.T) {
+	producer := &recordingProducer{}
+	k := NewKafka(producer, "audit-log")
+
+	entry := xablogger.Entry{TransactionID: "tx1", SegmentType: "sql"}
+	if err := k.Write(entry); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	if producer.topic != "audit-log" {
+		t.Errorf("topic = %q, want %q", producer.topic, "audit-log")
+	}
+	if string(producer.key) != "tx1" {
+		t.Errorf("key = %q, want %q (keyed by TransactionID)", producer.key, "tx1")
+	}
+
+	var got xablogger.Entry
+	if err := json.Unmarshal(producer.value, &got); err != nil {
+		t.Fatalf("decode published value: %v", err)
+	}
+	if got.TransactionID != "tx1" || got.SegmentType != "sql" {
+		t.Errorf("published entry = %+v, want TransactionID=tx1 SegmentType=sql", got)
+	}
+}
+
+func TestKafkaWritePropagatesProducerError(t *testing.T) {
+	wantErr := errors.New("broker unavailable")
+	producer := &recordingProducer{err: wantErr}
+	k := NewKafka(producer, "audit-log")
+
+	if err := k.Write(xablogger.Entry{TransactionID: "tx1"}); err != wantErr {
+		t.Errorf("Write() err = %v, want %v", err, wantErr)
+	}
+}
@@ -0,0 +1,42 @@
+// Package sinks provides built-in xablogger.Sink implementations for shipping log entries to common
+// analytics and logging backends.
+package sinks
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// Stdout is a Sink that writes each entry as a single line of JSON to an io.Writer, defaulting to os.Stdout.
+type Stdout struct {
+	Writer io.Writer
+	mux    sync.Mutex
+}
+
+// NewStdout builds a Stdout sink writing to os.Stdout
+func NewStdout() *Stdout {
+	return &Stdout{Writer: os.Stdout}
+}
+
+// Write encodes entry as JSON and writes it, followed by a newline
+func (s *Stdout) Write(entry xablogger.Entry) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	encoder := json.NewEncoder(s.Writer)
+	return encoder.Encode(entry)
+}
+
+// Flush is a no-op: every Write call already writes through to the underlying io.Writer
+func (s *Stdout) Flush() error {
+	return nil
+}
+
+// Close is a no-op: Stdout does not own the io.Writer it was given
+func (s *Stdout) Close() error {
+	return nil
+}
@@ -0,0 +1,38 @@
+package sinks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/andretanaka/xablogger"
+)
+
+func TestStdoutWriteEmitsOneJSONLinePerEntry(t *testing.T) {
+	var buf bytes.Buffer
+	s := &Stdout{Writer: &buf}
+
+	if err := s.Write(xablogger.Entry{TransactionID: "tx1", SegmentType: "http"}); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if err := s.Write(xablogger.Entry{TransactionID: "tx2", SegmentType: "sql"}); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	lines := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	var entries []xablogger.Entry
+	for lines.Scan() {
+		var e xablogger.Entry
+		if err := json.Unmarshal(lines.Bytes(), &e); err != nil {
+			t.Fatalf("decode line %q: %v", lines.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d lines, want 2 (one per Write call)", len(entries))
+	}
+	if entries[0].TransactionID != "tx1" || entries[1].TransactionID != "tx2" {
+		t.Errorf("entries = %+v, want TransactionID tx1 then tx2 in write order", entries)
+	}
+}
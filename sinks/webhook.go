@@ -0,0 +1,70 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// Webhook is a Sink that POSTs each entry as JSON to a configured URL.
+type Webhook struct {
+	// URL the entry is POSTed to
+	URL string
+	// Headers are added to every outgoing request, e.g. an Authorization header
+	Headers http.Header
+	// Client is the http.Client used to deliver entries. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// NewWebhook builds a Webhook sink posting to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{URL: url, Headers: make(http.Header), Client: http.DefaultClient}
+}
+
+// Write POSTs entry as a JSON body to URL
+func (s *Webhook) Write(entry xablogger.Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, values := range s.Headers {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("xablogger: webhook request to %s failed with status %d", s.URL, res.StatusCode)
+	}
+	return nil
+}
+
+// Flush is a no-op: each Write call is delivered immediately
+func (s *Webhook) Flush() error {
+	return nil
+}
+
+// Close is a no-op: Webhook holds no resources that need releasing
+func (s *Webhook) Close() error {
+	return nil
+}
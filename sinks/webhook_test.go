@@ -0,0 +1,64 @@
+package sinks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andretanaka/xablogger"
+)
+
+func TestWebhookWritePostsEntryAsJSON(t *testing.T) {
+	server := newCapturingServer(http.StatusOK)
+	defer server.close()
+
+	wh := NewWebhook(server.server.URL)
+	wh.Headers.Set("Authorization", "Bearer token")
+
+	if err := wh.Write(xablogger.Entry{TransactionID: "tx1", SegmentType: "http"}); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	if n := server.requestCount(); n != 1 {
+		t.Fatalf("requestCount() = %d, want 1", n)
+	}
+
+	var got xablogger.Entry
+	if err := json.Unmarshal(server.lastBody(), &got); err != nil {
+		t.Fatalf("decode posted body: %v", err)
+	}
+	if got.TransactionID != "tx1" || got.SegmentType != "http" {
+		t.Errorf("posted entry = %+v, want TransactionID=tx1 SegmentType=http", got)
+	}
+}
+
+func TestWebhookWriteErrorsOnNon2xxStatus(t *testing.T) {
+	server := newCapturingServer(http.StatusBadRequest)
+	defer server.close()
+
+	wh := NewWebhook(server.server.URL)
+
+	if err := wh.Write(xablogger.Entry{TransactionID: "tx1"}); err == nil {
+		t.Fatal("Write() = nil, want an error on a 400 response")
+	}
+}
+
+func TestWebhookWriteSendsHeaders(t *testing.T) {
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	wh := NewWebhook(server.URL)
+	wh.Headers.Set("Authorization", "Bearer secret")
+
+	if err := wh.Write(xablogger.Entry{TransactionID: "tx1"}); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if gotHeader != "Bearer secret" {
+		t.Errorf("Authorization header = %q, want %q", gotHeader, "Bearer secret")
+	}
+}
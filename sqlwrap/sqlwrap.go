@@ -0,0 +1,133 @@
+// Package sqlwrap provides a zero-boilerplate database/sql driver wrapper that automatically emits an
+// xablogger.SQLSegment for every context-aware Query/Exec call.
+package sqlwrap
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+
+	"github.com/andretanaka/xablogger"
+)
+
+var (
+	registerMux sync.Mutex
+	registered  = map[string]bool{}
+)
+
+// Open returns a *sql.DB backed by driverName/dsn whose every context-aware Query/Exec call automatically
+// creates and appends a xablogger.SQLSegment bound to the transaction carried by the call's context.Context.
+// Calls made through the non-Context methods (Query, Exec, QueryRow) carry no transaction and pass through
+// uninstrumented.
+func Open(driverName, dsn string) (*sql.DB, error) {
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	inner := probe.Driver()
+	probe.Close()
+
+	wrappedName := fmt.Sprintf("xablogger-%s", driverName)
+
+	registerMux.Lock()
+	if !registered[wrappedName] {
+		sql.Register(wrappedName, &wrappedDriver{driverName: driverName, inner: inner})
+		registered[wrappedName] = true
+	}
+	registerMux.Unlock()
+
+	return sql.Open(wrappedName, dsn)
+}
+
+// wrappedDriver delegates Open to the underlying driver, returning connections wrapped with segment
+// instrumentation.
+type wrappedDriver struct {
+	driverName string
+	inner      driver.Driver
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.inner.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{driverName: d.driverName, inner: conn}, nil
+}
+
+// wrappedConn delegates every driver.Conn method to inner, instrumenting the context-aware query/exec paths
+type wrappedConn struct {
+	driverName string
+	inner      driver.Conn
+}
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.inner.Prepare(query)
+}
+
+func (c *wrappedConn) Close() error {
+	return c.inner.Close()
+}
+
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.inner.Begin()
+}
+
+// QueryContext instruments queries made through *sql.DB's context-aware methods. If the underlying driver
+// does not support driver.QueryerContext, it returns driver.ErrSkip so database/sql falls back to
+// Prepare+Query.
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.inner.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	segment := xablogger.NewSQLSegment(ctx, c.driverName, query, namedValuesToParams(args))
+
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		segment.Failed(err)
+	}
+	segment.Done()
+	_ = xablogger.AppendSegmentContext(ctx, segment)
+
+	return rows, err
+}
+
+// ExecContext instruments statements executed through *sql.DB's context-aware methods. If the underlying
+// driver does not support driver.ExecerContext, it returns driver.ErrSkip so database/sql falls back to
+// Prepare+Exec.
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.inner.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	segment := xablogger.NewSQLSegment(ctx, c.driverName, query, namedValuesToParams(args))
+
+	res, err := execer.ExecContext(ctx, query, args)
+	if err != nil {
+		segment.Failed(err)
+	} else {
+		segment.ExecResponse(res)
+	}
+	segment.Done()
+	_ = xablogger.AppendSegmentContext(ctx, segment)
+
+	return res, err
+}
+
+// namedValuesToParams turns driver argument values into the map[string]interface{} xablogger.NewSQLSegment
+// expects, keying positional (unnamed) arguments by their ordinal position
+func namedValuesToParams(args []driver.NamedValue) map[string]interface{} {
+	params := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		key := arg.Name
+		if key == "" {
+			key = fmt.Sprintf("$%d", arg.Ordinal)
+		}
+		params[key] = arg.Value
+	}
+	return params
+}
@@ -0,0 +1,266 @@
+package sqlwrap
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andretanaka/xablogger"
+)
+
+// recordingSink captures every Entry written to it, safe for concurrent use since the sink pipeline
+// delivers from worker goroutines.
+type recordingSink struct {
+	mux     sync.Mutex
+	entries []xablogger.Entry
+}
+
+func (s *recordingSink) Write(e xablogger.Entry) error {
+	s.mux.Lock()
+	s.entries = append(s.entries, e)
+	s.mux.Unlock()
+	return nil
+}
+
+func (s *recordingSink) Flush() error { return nil }
+func (s *recordingSink) Close() error { return nil }
+
+func (s *recordingSink) len() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.entries)
+}
+
+// segmentEntryFor returns the per-segment (non-audit) entry recorded for transactionID, if any. Tests share
+// a single package-level sink across the whole binary, so matching on TransactionID keeps one test's
+// assertions immune to entries left behind by another.
+func (s *recordingSink) segmentEntryFor(transactionID string) (xablogger.Entry, bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, e := range s.entries {
+		if e.TransactionID == transactionID && !e.Audit {
+			return e, true
+		}
+	}
+	return xablogger.Entry{}, false
+}
+
+var (
+	testInitOnce sync.Once
+	testSink     = &recordingSink{}
+)
+
+func ensureTestInit() {
+	testInitOnce.Do(func() {
+		xablogger.Init(xablogger.Sinks(testSink), xablogger.Batching(1, time.Millisecond))
+	})
+}
+
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// fakeResult is a driver.Result test double reporting a fixed row count.
+type fakeResult struct{ rowsAffected int64 }
+
+func (r fakeResult) LastInsertId() (int64, error) { return 0, errors.New("not supported") }
+func (r fakeResult) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+// fakeRows is a driver.Rows test double exposing a single column and no rows.
+type fakeRows struct{ columns []string }
+
+func (r *fakeRows) Columns() []string              { return r.columns }
+func (r *fakeRows) Close() error                   { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+// fakeConn is a driver.Conn that also implements driver.QueryerContext/driver.ExecerContext, standing in
+// for a real database driver connection. queryErr/execErr let a test force the instrumented call to fail.
+type fakeConn struct {
+	queryErr error
+	execErr  error
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not implemented") }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.queryErr != nil {
+		return nil, c.queryErr
+	}
+	return &fakeRows{columns: []string{"id"}}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.execErr != nil {
+		return nil, c.execErr
+	}
+	return fakeResult{rowsAffected: 1}, nil
+}
+
+// plainConn is a driver.Conn that implements neither driver.QueryerContext nor driver.ExecerContext, like a
+// driver that only supports the legacy non-context Query/Exec path.
+type plainConn struct{}
+
+func (c *plainConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (c *plainConn) Close() error              { return nil }
+func (c *plainConn) Begin() (driver.Tx, error) { return nil, errors.New("not implemented") }
+
+func newTestContext(t *testing.T, txID string) context.Context {
+	t.Helper()
+	ensureTestInit()
+	ctx, err := xablogger.NewTransactionWithContext(context.Background(), txID)
+	if err != nil {
+		t.Fatalf("NewTransactionWithContext() = %v", err)
+	}
+	return ctx
+}
+
+func TestWrappedConnQueryContextEmitsSegment(t *testing.T) {
+	txID := "query-" + t.Name()
+	ctx := newTestContext(t, txID)
+	conn := &wrappedConn{driverName: "fake", inner: &fakeConn{}}
+
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(42)}}
+	rows, err := conn.QueryContext(ctx, "SELECT id FROM widgets WHERE id = $1", args)
+	if err != nil {
+		t.Fatalf("QueryContext() = %v", err)
+	}
+	if rows == nil {
+		t.Fatal("QueryContext() rows = nil, want the underlying driver's rows")
+	}
+
+	var entry xablogger.Entry
+	waitFor(t, time.Second, func() bool {
+		entry, _ = testSink.segmentEntryFor(txID)
+		return entry.SegmentType != ""
+	})
+
+	if entry.SegmentType != "sql" {
+		t.Errorf("SegmentType = %q, want %q", entry.SegmentType, "sql")
+	}
+	params, _ := entry.Fields["params"].(map[string]interface{})
+	if params["$1"] != int64(42) {
+		t.Errorf("params[$1] = %v, want 42 (positional ordinal key)", params["$1"])
+	}
+	if entry.Failed {
+		t.Error("Failed = true, want false for a successful query")
+	}
+}
+
+func TestWrappedConnQueryContextFailurePropagatesAndMarksSegment(t *testing.T) {
+	txID := "query-err-" + t.Name()
+	ctx := newTestContext(t, txID)
+	wantErr := errors.New("connection refused")
+	conn := &wrappedConn{driverName: "fake", inner: &fakeConn{queryErr: wantErr}}
+
+	_, err := conn.QueryContext(ctx, "SELECT 1", nil)
+	if err != wantErr {
+		t.Fatalf("QueryContext() err = %v, want %v", err, wantErr)
+	}
+
+	var entry xablogger.Entry
+	waitFor(t, time.Second, func() bool {
+		entry, _ = testSink.segmentEntryFor(txID)
+		return entry.SegmentType != ""
+	})
+
+	if !entry.Failed {
+		t.Error("Failed = false, want true after a failed query")
+	}
+}
+
+func TestWrappedConnExecContextEmitsSegment(t *testing.T) {
+	txID := "exec-" + t.Name()
+	ctx := newTestContext(t, txID)
+	conn := &wrappedConn{driverName: "fake", inner: &fakeConn{}}
+
+	args := []driver.NamedValue{{Name: "status", Value: "active"}}
+	res, err := conn.ExecContext(ctx, "UPDATE widgets SET status = :status", args)
+	if err != nil {
+		t.Fatalf("ExecContext() = %v", err)
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected != 1 {
+		t.Errorf("RowsAffected() = %d, want 1", rowsAffected)
+	}
+
+	var entry xablogger.Entry
+	waitFor(t, time.Second, func() bool {
+		entry, _ = testSink.segmentEntryFor(txID)
+		return entry.SegmentType != ""
+	})
+
+	params, _ := entry.Fields["params"].(map[string]interface{})
+	if params["status"] != "active" {
+		t.Errorf("params[status] = %v, want %q (named key)", params["status"], "active")
+	}
+	if entry.Fields["rows_affected"] != int64(1) {
+		t.Errorf("rows_affected = %v, want 1", entry.Fields["rows_affected"])
+	}
+}
+
+func TestWrappedConnExecContextFailureMarksSegment(t *testing.T) {
+	txID := "exec-err-" + t.Name()
+	ctx := newTestContext(t, txID)
+	wantErr := errors.New("constraint violation")
+	conn := &wrappedConn{driverName: "fake", inner: &fakeConn{execErr: wantErr}}
+
+	_, err := conn.ExecContext(ctx, "INSERT INTO widgets VALUES (1)", nil)
+	if err != wantErr {
+		t.Fatalf("ExecContext() err = %v, want %v", err, wantErr)
+	}
+
+	var entry xablogger.Entry
+	waitFor(t, time.Second, func() bool {
+		entry, _ = testSink.segmentEntryFor(txID)
+		return entry.SegmentType != ""
+	})
+
+	if !entry.Failed {
+		t.Error("Failed = false, want true after a failed exec")
+	}
+}
+
+func TestWrappedConnFallsThroughToErrSkip(t *testing.T) {
+	conn := &wrappedConn{driverName: "fake", inner: &plainConn{}}
+
+	if _, err := conn.QueryContext(context.Background(), "SELECT 1", nil); err != driver.ErrSkip {
+		t.Errorf("QueryContext() err = %v, want driver.ErrSkip", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), "SELECT 1", nil); err != driver.ErrSkip {
+		t.Errorf("ExecContext() err = %v, want driver.ErrSkip", err)
+	}
+}
+
+func TestNamedValuesToParams(t *testing.T) {
+	got := namedValuesToParams([]driver.NamedValue{
+		{Ordinal: 1, Value: "a"},
+		{Name: "id", Ordinal: 2, Value: 7},
+	})
+
+	if got["$1"] != "a" {
+		t.Errorf("params[$1] = %v, want %q (positional args keyed by ordinal)", got["$1"], "a")
+	}
+	if got["id"] != 7 {
+		t.Errorf("params[id] = %v, want 7 (named args keyed by name)", got["id"])
+	}
+	if len(got) != 2 {
+		t.Errorf("len(params) = %d, want 2", len(got))
+	}
+}
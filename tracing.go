@@ -0,0 +1,92 @@
+package xablogger
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies this package's spans inside the configured OpenTelemetry SDK
+const tracerName = "github.com/andretanaka/xablogger"
+
+// tracer is the OpenTelemetry tracer used to create segment spans. Users wire up a real SDK/exporter
+// through the regular otel.SetTracerProvider and xablogger will pick it up automatically
+var tracer = otel.Tracer(tracerName)
+
+// propagator handles encoding/decoding of the W3C traceparent/tracestate headers used to link segments
+// across process boundaries
+var propagator = propagation.TraceContext{}
+
+type contextKey string
+
+// transactionContextKey is the key under which the current *transaction is stored in a context.Context
+const transactionContextKey contextKey = "xablogger.transaction"
+
+// NewTransactionWithContext creates a new transaction, just like NewTransaction, but returns a context.Context
+// carrying the transaction so that callers don't need to thread the transaction ID through every function
+// signature. Use AppendSegmentContext and FlushTransactionContext to operate on the transaction stored in ctx.
+func NewTransactionWithContext(ctx context.Context, transactionID string) (context.Context, error) {
+	if err := NewTransaction(transactionID); err != nil {
+		return ctx, err
+	}
+
+	coordinatorInstance.mapMux.RLock()
+	tx := coordinatorInstance.transactionMap[transactionID]
+	coordinatorInstance.mapMux.RUnlock()
+
+	return context.WithValue(ctx, transactionContextKey, tx), nil
+}
+
+// transactionFromContext returns the transaction stored in ctx, if any
+func transactionFromContext(ctx context.Context) (*transaction, bool) {
+	tx, ok := ctx.Value(transactionContextKey).(*transaction)
+	return tx, ok
+}
+
+// AppendSegmentContext behaves like AppendSegment, but reads the transaction from ctx instead of requiring
+// the caller to pass its ID explicitly
+func AppendSegmentContext(ctx context.Context, segment Segment) error {
+	tx, ok := transactionFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("no xablogger transaction found in context")
+	}
+	return AppendSegment(tx.id, segment)
+}
+
+// FlushTransactionContext behaves like FlushTransaction, but reads the transaction from ctx instead of
+// requiring the caller to pass its ID explicitly
+func FlushTransactionContext(ctx context.Context) error {
+	tx, ok := transactionFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("no xablogger transaction found in context")
+	}
+	return FlushTransaction(tx.id)
+}
+
+// startSegmentSpan starts a child span for a segment under ctx, naming it spanName. The returned context
+// carries the new span so that further extraction/injection (and nested segments) keep the right parent.
+func startSegmentSpan(ctx context.Context, spanName string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, spanName)
+}
+
+// endSegmentSpan closes span, attaching the segment's fields as span attributes and marking it failed if
+// the segment reports an error
+func endSegmentSpan(span trace.Span, segment Segment) {
+	for k, v := range segment.Fields() {
+		span.SetAttributes(attribute.String(k, fmt.Sprintf("%v", v)))
+	}
+	if segment.HasFailed() {
+		span.RecordError(fmt.Errorf("%v", segment.Fields()["error"]))
+	}
+	span.End()
+}
+
+// injectTraceHeaders writes the current span's W3C traceparent/tracestate headers from ctx into headers,
+// so that downstream HTTP calls can be linked back to this segment's span
+func injectTraceHeaders(ctx context.Context, headers propagation.TextMapCarrier) {
+	propagator.Inject(ctx, headers)
+}
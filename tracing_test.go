@@ -0,0 +1,84 @@
+package xablogger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestNewTransactionWithContextRoundTrip(t *testing.T) {
+	ensureCoordinatorTestInit()
+
+	txID := "ctx-" + t.Name()
+	ctx, err := NewTransactionWithContext(context.Background(), txID)
+	if err != nil {
+		t.Fatalf("NewTransactionWithContext() = %v", err)
+	}
+
+	tx, ok := transactionFromContext(ctx)
+	if !ok {
+		t.Fatal("transactionFromContext() found no transaction in the returned context")
+	}
+	if tx.id != txID {
+		t.Errorf("transaction id = %q, want %q", tx.id, txID)
+	}
+
+	if err := AppendSegmentContext(ctx, newFakeSegment("ok", nil)); err != nil {
+		t.Fatalf("AppendSegmentContext() = %v", err)
+	}
+	if err := FlushTransactionContext(ctx); err != nil {
+		t.Fatalf("FlushTransactionContext() = %v", err)
+	}
+	if err := FlushTransactionContext(ctx); err == nil {
+		t.Error("FlushTransactionContext() on an already-flushed transaction should error")
+	}
+}
+
+func TestNewTransactionWithContextDuplicateID(t *testing.T) {
+	ensureCoordinatorTestInit()
+
+	txID := "ctx-dup-" + t.Name()
+	if _, err := NewTransactionWithContext(context.Background(), txID); err != nil {
+		t.Fatalf("NewTransactionWithContext() = %v", err)
+	}
+	if _, err := NewTransactionWithContext(context.Background(), txID); err == nil {
+		t.Error("NewTransactionWithContext() with a duplicate ID should error")
+	}
+}
+
+func TestTransactionFromContextNotFound(t *testing.T) {
+	if _, ok := transactionFromContext(context.Background()); ok {
+		t.Error("transactionFromContext() on a plain context should not find a transaction")
+	}
+}
+
+func TestAppendAndFlushSegmentContextWithoutTransaction(t *testing.T) {
+	if err := AppendSegmentContext(context.Background(), newFakeSegment("ok", nil)); err == nil {
+		t.Error("AppendSegmentContext() without a transaction in context should error")
+	}
+	if err := FlushTransactionContext(context.Background()); err == nil {
+		t.Error("FlushTransactionContext() without a transaction in context should error")
+	}
+}
+
+func TestStartAndEndSegmentSpan(t *testing.T) {
+	ctx, span := startSegmentSpan(context.Background(), "test.span")
+	if ctx == nil {
+		t.Fatal("startSegmentSpan() returned a nil context")
+	}
+
+	seg := newFakeSegment("ok", map[string]interface{}{"key": "value"})
+	endSegmentSpan(span, seg)
+
+	failing := newFakeSegment("bad", map[string]interface{}{})
+	failing.Failed(errors.New("boom"))
+	_, failingSpan := startSegmentSpan(context.Background(), "test.failing-span")
+	endSegmentSpan(failingSpan, failing)
+}
+
+func TestInjectTraceHeaders(t *testing.T) {
+	headers := propagation.MapCarrier{}
+	injectTraceHeaders(context.Background(), headers)
+}